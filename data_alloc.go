@@ -1,10 +1,27 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"sort"
 )
 
+type allocationError string
+
+func (e allocationError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrRiskOverflow    allocationError = "Error: max risk overflows int64."
+	ErrNoDataCenters   allocationError = "Error: no data centers to place fragments into."
+	ErrNonPositiveRisk allocationError = "Error: data center risk must be positive."
+
+	ErrInvalidErasureCode allocationError = "Error: erasure code must satisfy 1 <= k <= n and failures >= 0."
+	ErrUnsurvivable       allocationError = "Error: no placement survives the required data center failures."
+)
+
 func distributeFragments(dataCenters []int, fragments int) int64 {
 	if len(dataCenters) == 0 || fragments <= 0 {
 		return 0
@@ -30,16 +47,77 @@ func distributeFragments(dataCenters []int, fragments int) int64 {
 	return minRisk
 }
 
+// distributeFragmentsChecked is distributeFragments that reports errors instead of panicking.
+// The caller's slice is not reordered.
+func distributeFragmentsChecked(dataCenters []int, fragments int) (int64, error) {
+	if err := validateRisks(dataCenters); err != nil {
+		return 0, err
+	}
+	if fragments <= 0 {
+		return 0, nil
+	}
+	if len(dataCenters) == 0 {
+		return 0, ErrNoDataCenters
+	}
+
+	sorted := append([]int(nil), dataCenters...)
+	sort.Ints(sorted)
+
+	maxRisk, ok := upperRiskBound(sorted, fragments, ExponentialRisk{})
+	if !ok {
+		maxRisk = math.MaxInt64 // every estimation overflows, the answer still might not
+	}
+	return searchMinimalRisk(1, maxRisk, func(risk int64) bool {
+		return isRiskAchievable(risk, sorted, fragments)
+	})
+}
+
+func validateRisks(dataCenters []int) error {
+	for i, risk := range dataCenters {
+		if risk <= 0 {
+			return fmt.Errorf("%w: data center %d has risk %d", ErrNonPositiveRisk, i, risk)
+		}
+	}
+	return nil
+}
+
+// upperRiskBound is the max risk of spreading fragments evenly over the k least risky
+// data centers, the best k wins. Much tighter than mostRiskyDC^fragments.
+// sorted must be in ascending order, false means every estimation overflows.
+func upperRiskBound(sorted []int, fragments int, model RiskModel) (bound int64, ok bool) {
+	for k := 1; k <= len(sorted); k++ {
+		perDC := (fragments + k - 1) / k
+		risk, fits := model.Cost(sorted[k-1], perDC)
+		if fits && (!ok || risk < bound) {
+			bound, ok = risk, true
+		}
+	}
+	return bound, ok
+}
+
+// searchMinimalRisk returns the smallest risk in [minRisk, maxRisk] accepted by achievable.
+// achievable must be monotone. ErrRiskOverflow if even maxRisk is not enough.
+func searchMinimalRisk(minRisk, maxRisk int64, achievable func(int64) bool) (int64, error) {
+	if !achievable(maxRisk) {
+		return 0, ErrRiskOverflow
+	}
+	for minRisk < maxRisk {
+		mediumRisk := minRisk + (maxRisk-minRisk)/2 // (min+max)/2 overflows near math.MaxInt64
+		if achievable(mediumRisk) {
+			maxRisk = mediumRisk
+		} else {
+			minRisk = mediumRisk + 1
+		}
+	}
+	return minRisk, nil
+}
+
 // if a given maximum risk is achievable with the current configuration.
 func isRiskAchievable(maxRisk int64, dataCenters []int, fragments int) bool {
 	remainingFragments := fragments
 
 	for _, risk := range dataCenters {
-		count := 0
-
-		for (math.Pow(float64(risk), float64(count+1))) <= float64(maxRisk) {
-			count++ // putting fragment into datacenter if they fit
-		}
+		count := fragmentsUnderRisk(risk, maxRisk, remainingFragments)
 
 		remainingFragments -= count
 
@@ -51,6 +129,56 @@ func isRiskAchievable(maxRisk int64, dataCenters []int, fragments int) bool {
 	return false
 }
 
+// how many fragments (up to limit) fit into a data center without exceeding maxRisk.
+// The count is estimated with logarithms and verified with exact integer powers,
+// so it stays correct where float64 can't tell maxRisk from maxRisk-1 (above 2^53).
+// limit keeps risk 1 data centers from counting forever: 1^n never grows.
+func fragmentsUnderRisk(risk int, maxRisk int64, limit int) int {
+	if maxRisk < 1 || limit <= 0 {
+		return 0
+	}
+	if risk <= 1 {
+		return limit
+	}
+
+	count := int(math.Log(float64(maxRisk)) / math.Log(float64(risk)))
+	if count > limit { // estimation is off by one at most
+		return limit
+	}
+
+	power, fits := checkedPow(risk, count)
+	for !fits || power > maxRisk {
+		count--
+		power, fits = checkedPow(risk, count)
+	}
+	for count < limit && power <= maxRisk/int64(risk) {
+		power *= int64(risk) // putting fragment into datacenter if it fits
+		count++
+	}
+	return count
+}
+
+// checkedPow is base^exp by squaring, false if it does not fit into int64. base must not be negative.
+func checkedPow(base, exp int) (int64, bool) {
+	result, square := int64(1), int64(base)
+	for exp > 0 {
+		if exp&1 == 1 {
+			if square != 0 && result > math.MaxInt64/square {
+				return 0, false
+			}
+			result *= square
+		}
+		exp >>= 1
+		if exp > 0 {
+			if square != 0 && square > math.MaxInt64/square {
+				return 0, false
+			}
+			square *= square
+		}
+	}
+	return result, true
+}
+
 // otherwise overflow leads to integer being "wrap around": x int8 = 127; x++; x = -128
 func calculateMaxRisk(base, exp int) int64 {
 	result := int64(1)
@@ -62,3 +190,175 @@ func calculateMaxRisk(base, exp int) int64 {
 	}
 	return result
 }
+
+// DataCenterLoad is a part of the placement that landed in a single data center.
+type DataCenterLoad struct {
+	Index     int   // position of the data center in the input slice
+	Risk      int   // risk score of the data center
+	Fragments int   // fragments placed into the data center
+	Weight    int   // total weight of the fragments, only set by weighted placements
+	Cost      int64 // Risk^Fragments (or the RiskModel cost, of Weight if set), empty data center costs nothing
+}
+
+// Placement is a full allocation plan behind the minimal max risk.
+type Placement struct {
+	Loads      []DataCenterLoad // same order as the input slice
+	MaxRisk    int64
+	Bottleneck int // index of the data center holding MaxRisk, -1 if nothing is placed
+}
+
+// distributeFragmentsPlan returns the minimal max risk together with a placement achieving it.
+// The caller's slice is not reordered.
+// Tie-breaking is deterministic: fragments fill the least risky data centers first,
+// equal risks are filled in input order, and the bottleneck is the first data center
+// in input order whose cost equals the max risk.
+func distributeFragmentsPlan(dataCenters []int, fragments int) (int64, Placement) {
+	maxRisk := distributeFragments(append([]int(nil), dataCenters...), fragments)
+	return maxRisk, fillPlacement(dataCenters, fragments, maxRisk)
+}
+
+// riskOrder returns data center indexes sorted by risk, equal risks keep input order.
+func riskOrder[R int | float64](dataCenters []R) []int {
+	order := make([]int, len(dataCenters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return dataCenters[order[i]] < dataCenters[order[j]]
+	})
+	return order
+}
+
+// fillPlacement puts fragments greedily into the least risky data centers under maxRisk.
+func fillPlacement(dataCenters []int, fragments int, maxRisk int64) Placement {
+	return placeFragments(anonymousDataCenters(dataCenters), fragments, maxRisk, ExponentialRisk{})
+}
+
+// distributeErasureCoded places n fragments of a k-of-n erasure code (any required of total
+// fragments rebuild the object), so that losing any failures data centers still leaves
+// required fragments: the failures largest loads sum up to at most total-required.
+// The max risk is minimal among such placements, ErrUnsurvivable if there are none.
+func distributeErasureCoded(dataCenters []DataCenter, required, total, failures int) (NamedPlacement, error) {
+	if required < 1 || total < required || failures < 0 {
+		return NamedPlacement{}, fmt.Errorf("%w: %d of %d, %d failures", ErrInvalidErasureCode, required, total, failures)
+	}
+	if err := validateDataCenters(dataCenters); err != nil {
+		return NamedPlacement{}, err
+	}
+
+	if len(dataCenters) == 0 {
+		return NamedPlacement{}, ErrNoDataCenters
+	}
+
+	var (
+		spare    = total - required // fragments the object can afford to lose
+		minRisk  int64
+		minimums int
+		caps     = make([]int, len(dataCenters))
+	)
+	for _, dc := range dataCenters {
+		minimums += dc.MinFragments
+		risk, ok := ExponentialRisk{}.Cost(dc.Risk, dc.MinFragments)
+		if !ok {
+			return NamedPlacement{}, fmt.Errorf("%w: data center %s minimum", ErrRiskOverflow, dc.ID)
+		}
+		minRisk = max(minRisk, risk)
+	}
+	if minimums > total {
+		return NamedPlacement{}, fmt.Errorf("%w: %d required, %d to place", ErrMinimumExceedsFragments, minimums, total)
+	}
+
+	layout := func(maxRisk int64) ([]int, bool) {
+		for i, dc := range dataCenters {
+			caps[i] = dc.capacity(maxRisk, total, ExponentialRisk{})
+		}
+		return survivableLayout(dataCenters, caps, total, spare, failures)
+	}
+	achievable := func(maxRisk int64) bool {
+		_, ok := layout(maxRisk)
+		return maxRisk >= minRisk && ok
+	}
+
+	maxRisk, err := searchMinimalRisk(minRisk, math.MaxInt64, achievable)
+	if errors.Is(err, ErrRiskOverflow) {
+		quota := 0
+		for i, dc := range dataCenters { // would any risk do?
+			caps[i] = total
+			if dc.MaxFragments != Unlimited {
+				caps[i] = min(total, dc.MaxFragments)
+			}
+			quota += caps[i]
+		}
+		if quota < total {
+			return NamedPlacement{}, fmt.Errorf("%w: quota %d, %d to place", ErrInsufficientCapacity, quota, total)
+		}
+		if _, ok := survivableLayout(dataCenters, caps, total, spare, failures); !ok {
+			return NamedPlacement{}, fmt.Errorf("%w: %d of %d, %d failures", ErrUnsurvivable, required, total, failures)
+		}
+	}
+	if err != nil {
+		return NamedPlacement{}, err
+	}
+
+	counts, _ := layout(maxRisk)
+	placement := Placement{Loads: make([]DataCenterLoad, len(dataCenters))}
+	for i, dc := range dataCenters {
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: counts[i]}
+	}
+	priceLoads(&placement, ExponentialRisk{})
+	return namePlacement(dataCenters, placement), nil
+}
+
+// survivableLayout spreads total fragments within [MinFragments, caps] so that the failures
+// largest loads sum up to at most spare, false if it is impossible.
+//
+// The sum of the f largest loads is min over λ of f*λ + Σ max(0, load-λ), so for a fixed λ
+// every data center takes up to λ fragments for free, and each fragment above λ spends one
+// of spare-f*λ. Trying every λ finds the largest total that fits.
+func survivableLayout(dataCenters []DataCenter, caps []int, total, spare, failures int) ([]int, bool) {
+	counts := make([]int, len(dataCenters))
+	for i, dc := range dataCenters {
+		if caps[i] < dc.MinFragments {
+			return nil, false
+		}
+	}
+
+	maxLambda := total // no failures to survive: nothing above λ
+	if failures > 0 {
+		maxLambda = spare / failures
+	}
+	order := riskOrder(risksOf(dataCenters))
+
+	for lambda := 0; lambda <= maxLambda; lambda++ {
+		budget, placed := total, 0
+		if failures > 0 {
+			budget = spare - failures*lambda
+		}
+		for i, dc := range dataCenters {
+			counts[i] = max(min(caps[i], lambda), dc.MinFragments)
+			budget -= max(counts[i]-lambda, 0) // minimums above λ are paid anyway
+			placed += counts[i]
+		}
+		if budget < 0 {
+			continue
+		}
+		for _, i := range order { // least risky data centers go above λ first
+			extra := min(caps[i]-counts[i], budget)
+			counts[i] += extra
+			budget -= extra
+			placed += extra
+		}
+		if placed < total {
+			continue
+		}
+
+		for n := len(order) - 1; n >= 0 && placed > total; n-- { // fewer fragments never hurt survival
+			i := order[n]
+			extra := min(counts[i]-dataCenters[i].MinFragments, placed-total)
+			counts[i] -= extra
+			placed -= extra
+		}
+		return counts, true
+	}
+	return nil, false
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// distributeFragmentsBig is distributeFragmentsChecked without the int64 ceiling.
+// Both the binary search and the feasibility check run on big integers, so the answer is exact
+// for any input size. When the upper bound provably fits into int64 the fast
+// int64 search is used instead, it compares exact integer powers as well.
+func distributeFragmentsBig(dataCenters []int, fragments int) (*big.Int, error) {
+	if err := validateRisks(dataCenters); err != nil {
+		return nil, err
+	}
+	if fragments <= 0 {
+		return new(big.Int), nil
+	}
+	if len(dataCenters) == 0 {
+		return nil, ErrNoDataCenters
+	}
+
+	sorted := append([]int(nil), dataCenters...)
+	sort.Ints(sorted)
+
+	maxRisk := bigUpperRiskBound(sorted, fragments)
+	if maxRisk.IsInt64() { // fast path
+		risk, err := distributeFragmentsChecked(sorted, fragments)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(risk), nil
+	}
+
+	minRisk := big.NewInt(1)
+	mediumRisk := new(big.Int)
+	for minRisk.Cmp(maxRisk) < 0 {
+		mediumRisk.Add(minRisk, maxRisk).Rsh(mediumRisk, 1)
+		if isBigRiskAchievable(mediumRisk, sorted, fragments) {
+			maxRisk.Set(mediumRisk)
+		} else {
+			minRisk.Add(mediumRisk, big.NewInt(1))
+		}
+	}
+	return minRisk, nil
+}
+
+// bigUpperRiskBound is upperRiskBound in big integers. The best k is picked in log space,
+// any k gives a valid bound so float imprecision only costs a few search steps.
+func bigUpperRiskBound(sorted []int, fragments int) *big.Int {
+	bestK, bestLog := 1, math.Inf(1)
+	for k := 1; k <= len(sorted); k++ {
+		perDC := (fragments + k - 1) / k
+		if logRisk := float64(perDC) * math.Log2(float64(sorted[k-1])); logRisk < bestLog {
+			bestK, bestLog = k, logRisk
+		}
+	}
+	perDC := (fragments + bestK - 1) / bestK
+	return bigPow(sorted[bestK-1], perDC)
+}
+
+func isBigRiskAchievable(maxRisk *big.Int, dataCenters []int, fragments int) bool {
+	remainingFragments := fragments
+
+	for _, risk := range dataCenters {
+		remainingFragments -= bigFragmentsUnderRisk(risk, maxRisk, remainingFragments)
+
+		if remainingFragments <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bigFragmentsUnderRisk is the exact max count (up to limit) with risk^count <= maxRisk.
+// The count is estimated with logarithms and then verified with integer powers.
+func bigFragmentsUnderRisk(risk int, maxRisk *big.Int, limit int) int {
+	if maxRisk.Sign() <= 0 {
+		return 0
+	}
+	if risk == 1 {
+		return limit
+	}
+
+	count := int(bigLog2(maxRisk) / math.Log2(float64(risk)))
+	if count > limit { // estimation is off by one at most
+		return limit
+	}
+
+	bigRisk := big.NewInt(int64(risk))
+	power := bigPow(risk, count)
+	for count > 0 && power.Cmp(maxRisk) > 0 {
+		power.Quo(power, bigRisk)
+		count--
+	}
+	for count < limit && new(big.Int).Mul(power, bigRisk).Cmp(maxRisk) <= 0 {
+		power.Mul(power, bigRisk)
+		count++
+	}
+	return count
+}
+
+func bigPow(base, exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(exp)), nil)
+}
+
+// bigLog2 keeps the 64 most significant bits, enough for a float64 mantissa.
+func bigLog2(x *big.Int) float64 {
+	shift := x.BitLen() - 64
+	if shift <= 0 {
+		return math.Log2(float64(x.Uint64()))
+	}
+	top := new(big.Int).Rsh(x, uint(shift))
+	return math.Log2(float64(top.Uint64())) + float64(shift)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+// nthSmallestRisk is a brute force answer: the minimal max risk is the fragments-th smallest
+// value among all risk^count, count >= 1.
+func nthSmallestRisk(dataCenters []int, fragments int) *big.Int {
+	var values []*big.Int
+	for _, risk := range dataCenters {
+		for count := 1; count <= fragments; count++ {
+			values = append(values, bigPow(risk, count))
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	return values[fragments-1]
+}
+
+func TestDistributeFragmentsBig(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataCenters []int
+		fragments   int
+		expected    string
+	}{
+		{"Simple", []int{10, 30, 20}, 5, "400"},
+		{"EqualRisks", []int{10, 10, 10}, 4, "100"},
+		{"HighRisk", []int{1000000}, 2, "1000000000000"},
+		{"BeyondFloatPrecision", []int{3, 7, 11}, 60, "232630513987207"},
+		{"BeyondInt64", []int{1000, 2000}, 20, "1000000000000000000000000000000000"},
+		{"ManyCenters", repeatRisk(50, 30), 400, "610351562500000000000000"},
+		{"PowerOfTwo", []int{2}, 100, "1267650600228229401496703205376"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := distributeFragmentsBig(tt.dataCenters, tt.fragments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("Expected %s, but got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDistributeFragmentsBig_BruteForce(t *testing.T) {
+	inputs := [][]int{{2, 3}, {7, 5, 13, 2}, {999, 1000, 1001}, {1, 64}, {17}}
+	for _, dataCenters := range inputs {
+		for fragments := 1; fragments <= 70; fragments += 7 {
+			expected := nthSmallestRisk(dataCenters, fragments)
+			result, err := distributeFragmentsBig(dataCenters, fragments)
+			if err != nil || result.Cmp(expected) != 0 {
+				t.Errorf("%v, %d: Expected %s, but got %s, %v", dataCenters, fragments, expected, result, err)
+			}
+		}
+	}
+}
+
+func TestDistributeFragmentsBig_Errors(t *testing.T) {
+	if _, err := distributeFragmentsBig(nil, 3); !errors.Is(err, ErrNoDataCenters) {
+		t.Errorf("Expected %v, but got %v", ErrNoDataCenters, err)
+	}
+	if _, err := distributeFragmentsBig([]int{5, -1}, 3); !errors.Is(err, ErrNonPositiveRisk) {
+		t.Errorf("Expected %v, but got %v", ErrNonPositiveRisk, err)
+	}
+	if result, err := distributeFragmentsBig([]int{5}, 0); err != nil || result.Sign() != 0 {
+		t.Errorf("Expected 0, but got %s, %v", result, err)
+	}
+}
+
+func repeatRisk(risk, n int) []int {
+	dataCenters := make([]int, n)
+	for i := range dataCenters {
+		dataCenters[i] = risk
+	}
+	return dataCenters
+}
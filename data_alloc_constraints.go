@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	ErrInvalidFragmentID        allocationError = "Error: fragment IDs must be unique and non-empty."
+	ErrUnsatisfiableConstraints allocationError = "Error: placement constraints can't be satisfied."
+)
+
+// FragmentRule restricts where a single fragment may go.
+type FragmentRule struct {
+	ID      string
+	Pinned  string   // data center already holding the fragment, empty if it is free
+	Allowed []string // data centers the fragment may use, empty means any
+	Denied  []string // data centers the fragment must avoid
+}
+
+// ConstrainedPlacement is a placement of individually constrained fragments.
+type ConstrainedPlacement struct {
+	NamedPlacement
+	Assignment map[string]string // fragment ID -> data center ID, pinned ones included
+}
+
+// distributeConstrainedFragments minimizes the max risk while every fragment stays within its
+// allow/deny lists. Pinned fragments are already consumed load: they raise the lowest possible
+// bound and take the data center's capacity. Free fragments are matched to data centers
+// (a bipartite b-matching, capacities come from the probed bound).
+// MinFragments is rejected with ErrInvalidCapacity, pins express minimums here.
+// An unsatisfiable input fails with ErrUnsatisfiableConstraints naming the fragments and the
+// only data centers they may use, which together don't have enough room.
+func distributeConstrainedFragments(dataCenters []DataCenter, fragments []FragmentRule) (ConstrainedPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return ConstrainedPlacement{}, err
+	}
+	index := make(map[string]int, len(dataCenters))
+	for i, dc := range dataCenters {
+		if dc.MinFragments > 0 {
+			return ConstrainedPlacement{}, fmt.Errorf("%w: data center %s has minimum %d, pin fragments instead", ErrInvalidCapacity, dc.ID, dc.MinFragments)
+		}
+		index[dc.ID] = i
+	}
+
+	var (
+		pinned  = make([]int, len(dataCenters))
+		free    []int   // fragment indexes
+		options [][]int // data center indexes per free fragment, least risky first
+		order   = riskOrder(risksOf(dataCenters))
+		seen    = make(map[string]struct{}, len(fragments))
+	)
+	for n, fragment := range fragments {
+		if _, ok := seen[fragment.ID]; ok || fragment.ID == "" {
+			return ConstrainedPlacement{}, fmt.Errorf("%w: %q", ErrInvalidFragmentID, fragment.ID)
+		}
+		seen[fragment.ID] = struct{}{}
+
+		for _, id := range slices.Concat([]string{fragment.Pinned}, fragment.Allowed, fragment.Denied) {
+			if _, ok := index[id]; !ok && id != "" {
+				return ConstrainedPlacement{}, fmt.Errorf("%w: fragment %s refers to unknown data center %q", ErrInvalidDataCenterID, fragment.ID, id)
+			}
+		}
+
+		permitted := make([]int, 0, len(order))
+		for _, i := range order {
+			if fragment.permits(dataCenters[i].ID) {
+				permitted = append(permitted, i)
+			}
+		}
+
+		if fragment.Pinned != "" {
+			if !fragment.permits(fragment.Pinned) {
+				return ConstrainedPlacement{}, fmt.Errorf("%w: fragment %s is pinned to %s it may not use", ErrUnsatisfiableConstraints, fragment.ID, fragment.Pinned)
+			}
+			pinned[index[fragment.Pinned]]++
+			continue
+		}
+		if len(permitted) == 0 {
+			return ConstrainedPlacement{}, fmt.Errorf("%w: fragment %s may not use any data center", ErrUnsatisfiableConstraints, fragment.ID)
+		}
+		free = append(free, n)
+		options = append(options, permitted)
+	}
+
+	var minRisk int64
+	for i, dc := range dataCenters {
+		if dc.MaxFragments != Unlimited && pinned[i] > dc.MaxFragments {
+			return ConstrainedPlacement{}, fmt.Errorf("%w: %d fragments pinned to %s with quota %d", ErrUnsatisfiableConstraints, pinned[i], dc.ID, dc.MaxFragments)
+		}
+		risk, ok := ExponentialRisk{}.Cost(dc.Risk, pinned[i])
+		if !ok {
+			return ConstrainedPlacement{}, fmt.Errorf("%w: fragments pinned to %s", ErrRiskOverflow, dc.ID)
+		}
+		minRisk = max(minRisk, risk)
+	}
+
+	matching := func(maxRisk int64) *fragmentMatching {
+		m := newFragmentMatching(options, len(dataCenters))
+		for i, dc := range dataCenters {
+			if maxRisk < 0 { // quotas only
+				m.caps[i] = len(free)
+				if dc.MaxFragments != Unlimited {
+					m.caps[i] = min(m.caps[i], dc.MaxFragments-pinned[i])
+				}
+			} else {
+				m.caps[i] = dc.capacity(maxRisk, pinned[i]+len(free), ExponentialRisk{}) - pinned[i]
+			}
+		}
+		m.match()
+		return m
+	}
+
+	if m := matching(-1); m.failed >= 0 {
+		return ConstrainedPlacement{}, m.explain(dataCenters, fragments, free)
+	}
+	maxRisk, err := searchMinimalRisk(minRisk, math.MaxInt64, func(maxRisk int64) bool {
+		return maxRisk >= minRisk && matching(maxRisk).failed < 0
+	})
+	if err != nil {
+		return ConstrainedPlacement{}, err
+	}
+
+	m := matching(maxRisk)
+	result := ConstrainedPlacement{Assignment: make(map[string]string, len(fragments))}
+	placement := Placement{Loads: make([]DataCenterLoad, len(dataCenters))}
+	for i, dc := range dataCenters {
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: pinned[i] + len(m.holders[i])}
+	}
+	for _, fragment := range fragments {
+		if fragment.Pinned != "" {
+			result.Assignment[fragment.ID] = fragment.Pinned
+		}
+	}
+	for k, i := range m.assigned {
+		result.Assignment[fragments[free[k]].ID] = dataCenters[i].ID
+	}
+	priceLoads(&placement, ExponentialRisk{})
+
+	result.NamedPlacement = namePlacement(dataCenters, placement)
+	return result, nil
+}
+
+func (f FragmentRule) permits(dataCenter string) bool {
+	if len(f.Allowed) > 0 && !slices.Contains(f.Allowed, dataCenter) {
+		return false
+	}
+	return !slices.Contains(f.Denied, dataCenter)
+}
+
+// fragmentMatching assigns free fragments to data centers with augmenting paths:
+// a fragment takes a data center with room, or evicts a fragment that can move elsewhere.
+type fragmentMatching struct {
+	options  [][]int
+	caps     []int
+	holders  [][]int // data center -> free fragments it holds
+	assigned []int   // free fragment -> data center
+	visited  []bool  // data centers on the current augmenting search
+	failed   int     // first fragment that found no place, -1 if all matched
+}
+
+func newFragmentMatching(options [][]int, dataCenters int) *fragmentMatching {
+	return &fragmentMatching{
+		options:  options,
+		caps:     make([]int, dataCenters),
+		holders:  make([][]int, dataCenters),
+		assigned: make([]int, len(options)),
+		visited:  make([]bool, dataCenters),
+		failed:   -1,
+	}
+}
+
+func (m *fragmentMatching) match() {
+	for fragment := range m.options {
+		clear(m.visited)
+		if !m.augment(fragment) {
+			m.failed = fragment
+			return
+		}
+	}
+}
+
+func (m *fragmentMatching) augment(fragment int) bool {
+	for _, dc := range m.options[fragment] {
+		if m.visited[dc] {
+			continue
+		}
+		m.visited[dc] = true
+
+		if len(m.holders[dc]) < m.caps[dc] {
+			m.holders[dc] = append(m.holders[dc], fragment)
+			m.assigned[fragment] = dc
+			return true
+		}
+		for k, other := range m.holders[dc] {
+			if m.augment(other) {
+				m.holders[dc][k] = fragment
+				m.assigned[fragment] = dc
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// explain describes the failed augmenting search: the data centers it visited are the only
+// ones the failed fragment and their current holders may use, and all of them are full.
+func (m *fragmentMatching) explain(dataCenters []DataCenter, fragments []FragmentRule, free []int) error {
+	var (
+		stuck = []string{fragments[free[m.failed]].ID}
+		used  []string
+		room  int
+	)
+	for dc, visited := range m.visited {
+		if !visited {
+			continue
+		}
+		used = append(used, dataCenters[dc].ID)
+		room += max(m.caps[dc], 0)
+		for _, other := range m.holders[dc] {
+			stuck = append(stuck, fragments[free[other]].ID)
+		}
+	}
+	sort.Strings(stuck)
+	sort.Strings(used)
+	return fmt.Errorf("%w: fragments %s may only use data centers %s with room for %d",
+		ErrUnsatisfiableConstraints, strings.Join(stuck, ", "), strings.Join(used, ", "), room)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDistributeConstrainedFragments(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}
+	tests := []struct {
+		name      string
+		fragments []FragmentRule
+		maxRisk   int64
+		expected  map[string]int
+		assigned  map[string]string
+	}{
+		{
+			name:      "FreeMatchesNamed",
+			fragments: []FragmentRule{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}},
+			maxRisk:   400,
+			expected:  map[string]int{"fra": 2, "ams": 1, "lon": 2},
+		},
+		{
+			name: "Pinned",
+			fragments: []FragmentRule{
+				{ID: "a", Pinned: "ams"}, {ID: "b", Pinned: "ams"}, {ID: "c"}, {ID: "d"},
+			},
+			maxRisk:  900,
+			expected: map[string]int{"fra": 2, "ams": 2, "lon": 0},
+			assigned: map[string]string{"a": "ams", "b": "ams", "c": "fra", "d": "fra"},
+		},
+		{
+			name: "Denied",
+			fragments: []FragmentRule{
+				{ID: "a", Denied: []string{"fra", "lon"}}, {ID: "b", Denied: []string{"fra"}}, {ID: "c", Denied: []string{"fra"}},
+			},
+			maxRisk:  400,
+			expected: map[string]int{"fra": 0, "ams": 1, "lon": 2},
+			assigned: map[string]string{"a": "ams"},
+		},
+		{
+			name: "AllowedEvictsFreeFragment",
+			fragments: []FragmentRule{
+				{ID: "a"}, {ID: "b", Allowed: []string{"fra"}}, {ID: "c", Allowed: []string{"fra"}},
+			},
+			maxRisk:  100,
+			expected: map[string]int{"fra": 2, "ams": 0, "lon": 1},
+			assigned: map[string]string{"a": "lon", "b": "fra", "c": "fra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := distributeConstrainedFragments(dataCenters, tt.fragments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk {
+				t.Errorf("Expected %d, but got %d", tt.maxRisk, placement.MaxRisk)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+			for fragment, id := range tt.assigned {
+				if placement.Assignment[fragment] != id {
+					t.Errorf("Expected %s in %s, but got %s", fragment, id, placement.Assignment[fragment])
+				}
+			}
+			if len(placement.Assignment) != len(tt.fragments) {
+				t.Errorf("Expected %d assigned fragments, but got %v", len(tt.fragments), placement.Assignment)
+			}
+		})
+	}
+}
+
+func TestDistributeConstrainedFragments_Errors(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: 1}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}}
+	tests := []struct {
+		name      string
+		fragments []FragmentRule
+		expected  error
+		mentions  []string
+	}{
+		{
+			name:      "NotEnoughRoom",
+			fragments: []FragmentRule{{ID: "a", Allowed: []string{"fra"}}, {ID: "b", Allowed: []string{"fra"}}, {ID: "c"}},
+			expected:  ErrUnsatisfiableConstraints,
+			mentions:  []string{"fragments a, b", "data centers fra", "room for 1"},
+		},
+		{
+			name:      "NothingPermitted",
+			fragments: []FragmentRule{{ID: "a", Denied: []string{"fra", "ams"}}},
+			expected:  ErrUnsatisfiableConstraints,
+			mentions:  []string{"fragment a"},
+		},
+		{
+			name:      "PinnedToDenied",
+			fragments: []FragmentRule{{ID: "a", Pinned: "ams", Denied: []string{"ams"}}},
+			expected:  ErrUnsatisfiableConstraints,
+			mentions:  []string{"pinned to ams"},
+		},
+		{
+			name:      "PinnedAboveQuota",
+			fragments: []FragmentRule{{ID: "a", Pinned: "fra"}, {ID: "b", Pinned: "fra"}},
+			expected:  ErrUnsatisfiableConstraints,
+			mentions:  []string{"quota 1"},
+		},
+		{
+			name:      "UnknownDataCenter",
+			fragments: []FragmentRule{{ID: "a", Allowed: []string{"par"}}},
+			expected:  ErrInvalidDataCenterID,
+		},
+		{
+			name:      "DuplicateFragment",
+			fragments: []FragmentRule{{ID: "a"}, {ID: "a"}},
+			expected:  ErrInvalidFragmentID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := distributeConstrainedFragments(dataCenters, tt.fragments)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, but got %v", tt.expected, err)
+			}
+			for _, mention := range tt.mentions {
+				if !strings.Contains(err.Error(), mention) {
+					t.Errorf("Expected %q in %q", mention, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeConstrainedFragments_MinFragments(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MinFragments: 1, MaxFragments: Unlimited}}
+	if _, err := distributeConstrainedFragments(dataCenters, []FragmentRule{{ID: "a"}}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected %v, but got %v", ErrInvalidCapacity, err)
+	}
+}
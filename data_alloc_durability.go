@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	ErrInvalidSimulation allocationError = "Error: simulation config or failure profile is invalid."
+
+	hoursPerYear = 365 * 24
+	z95          = 1.959964 // two-sided 95% normal quantile
+)
+
+// FailureProfile is how a data center fails. Outages arrive as a Poisson process.
+type FailureProfile struct {
+	AnnualFailureProbability float64 // chance of at least one outage in a year, [0, 1)
+	RepairHours              float64 // fragments of a failed data center are back after that
+	DestructionProbability   float64 // chance an outage destroys the fragments, [0, 1], needs RepairHours > 0
+}
+
+// SimulationConfig drives simulateDurability, trials with the same seed give the same report.
+type SimulationConfig struct {
+	Trials   int
+	Seed     int64
+	Required int     // fragments needed to read the object, 0 means all of them
+	Hours    float64 // simulated period, a year if zero
+}
+
+// DurabilityReport is the outcome of a simulation. The object is unavailable while fewer
+// than Required fragments are up. Destroyed fragments are rebuilt from the surviving ones
+// within the repair time, the object is lost for good once fewer than Required fragments
+// survive: nothing is left to rebuild from, it stays unavailable till the end.
+type DurabilityReport struct {
+	Trials                    int
+	LossProbability           float64    // chance of a permanent loss
+	LossInterval              [2]float64 // 95% Wilson score interval
+	UnavailabilityProbability float64    // chance of being unavailable at least once, losses included
+	UnavailabilityInterval    [2]float64 // 95% Wilson score interval
+	ExpectedUnavailableHours  float64
+	UnavailableInterval       [2]float64 // 95% normal interval of the mean
+}
+
+// simulateDurability runs seeded Monte Carlo trials of data center outages over the placement.
+func simulateDurability(placement NamedPlacement, profiles map[string]FailureProfile, config SimulationConfig) (DurabilityReport, error) {
+	if config.Trials <= 0 || config.Required < 0 || config.Hours < 0 {
+		return DurabilityReport{}, fmt.Errorf("%w: %+v", ErrInvalidSimulation, config)
+	}
+	if config.Hours == 0 {
+		config.Hours = hoursPerYear
+	}
+
+	type site struct {
+		fragments   int
+		ratePerHour float64
+		repairHours float64
+		destruction float64
+	}
+	var (
+		sites []site
+		total int
+	)
+	ids := make([]string, 0, len(placement.Loads))
+	for id := range placement.Loads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // map order must not change which random numbers go where
+
+	for _, id := range ids {
+		load := placement.Loads[id]
+		if load.Fragments <= 0 {
+			continue
+		}
+		profile, ok := profiles[id]
+		if !ok || profile.AnnualFailureProbability < 0 || profile.AnnualFailureProbability >= 1 || profile.RepairHours < 0 ||
+			profile.DestructionProbability < 0 || profile.DestructionProbability > 1 ||
+			profile.DestructionProbability > 0 && profile.RepairHours == 0 { // an instant rebuild would hide the loss
+			return DurabilityReport{}, fmt.Errorf("%w: data center %s has profile %+v", ErrInvalidSimulation, id, profile)
+		}
+		sites = append(sites, site{
+			fragments:   load.Fragments,
+			ratePerHour: -math.Log1p(-profile.AnnualFailureProbability) / hoursPerYear,
+			repairHours: profile.RepairHours,
+			destruction: profile.DestructionProbability,
+		})
+		total += load.Fragments
+	}
+
+	required := config.Required
+	if required == 0 {
+		required = total
+	}
+	if required > total {
+		return DurabilityReport{}, fmt.Errorf("%w: %d required, %d placed", ErrInvalidSimulation, required, total)
+	}
+
+	var (
+		rng                 = rand.New(rand.NewSource(config.Seed))
+		losses, unavailable int
+		sum, sumSquares     float64
+		events              []outageEvent
+	)
+	for trial := 0; trial < config.Trials; trial++ {
+		events = events[:0]
+		for _, s := range sites {
+			events = appendOutages(events, rng, s.fragments, s.ratePerHour, s.repairHours, s.destruction, config.Hours)
+		}
+
+		hours, lost := unavailableHours(events, total-required, config.Hours)
+		if lost {
+			losses++
+		}
+		if hours > 0 || lost {
+			unavailable++
+		}
+		sum += hours
+		sumSquares += hours * hours
+	}
+
+	n := float64(config.Trials)
+	report := DurabilityReport{
+		Trials:                    config.Trials,
+		LossProbability:           float64(losses) / n,
+		LossInterval:              wilsonInterval(losses, config.Trials),
+		UnavailabilityProbability: float64(unavailable) / n,
+		UnavailabilityInterval:    wilsonInterval(unavailable, config.Trials),
+		ExpectedUnavailableHours:  sum / n,
+	}
+
+	variance := 0.0
+	if config.Trials > 1 {
+		variance = max(sumSquares-sum*sum/n, 0) / (n - 1)
+	}
+	half := z95 * math.Sqrt(variance/n)
+	report.UnavailableInterval = [2]float64{max(report.ExpectedUnavailableHours-half, 0), report.ExpectedUnavailableHours + half}
+	return report, nil
+}
+
+// outageEvent is a data center going down (positive) or coming back (negative).
+// Destroyed fragments are down as well, they come back rebuilt.
+type outageEvent struct {
+	hour      float64
+	fragments int
+	destroyed int
+}
+
+// appendOutages draws Poisson outages of a single data center within hours. An outage that
+// arrives while the data center is still down just extends the repair, a destructive one
+// destroys the fragments from its arrival till the repair is over.
+func appendOutages(events []outageEvent, rng *rand.Rand, fragments int, ratePerHour, repairHours, destruction, hours float64) []outageEvent {
+	if ratePerHour <= 0 || repairHours <= 0 {
+		return events
+	}
+
+	down, up, destroyed := -1.0, -1.0, -1.0
+	flush := func() {
+		end := min(up, hours)
+		events = append(events, outageEvent{hour: down, fragments: fragments}, outageEvent{hour: end, fragments: -fragments})
+		if destroyed >= 0 {
+			events = append(events, outageEvent{hour: destroyed, destroyed: fragments}, outageEvent{hour: end, destroyed: -fragments})
+		}
+	}
+	for at := rng.ExpFloat64() / ratePerHour; at < hours; at += rng.ExpFloat64() / ratePerHour {
+		destructive := destruction > 0 && rng.Float64() < destruction
+		if down >= 0 && at <= up {
+			up = max(up, at+repairHours)
+			if destructive && destroyed < 0 {
+				destroyed = at
+			}
+			continue
+		}
+		if down >= 0 {
+			flush()
+		}
+		down, up, destroyed = at, at+repairHours, -1
+		if destructive {
+			destroyed = at
+		}
+	}
+	if down >= 0 {
+		flush()
+	}
+	return events
+}
+
+// unavailableHours is how long more than spare fragments were down at once, and whether
+// more than spare were destroyed at once. A lost object is unavailable till hours.
+func unavailableHours(events []outageEvent, spare int, hours float64) (unavailable float64, lost bool) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].hour != events[j].hour {
+			return events[i].hour < events[j].hour
+		}
+		// repairs first, touching outages don't overlap
+		return events[i].fragments+events[i].destroyed < events[j].fragments+events[j].destroyed
+	})
+
+	var (
+		down, destroyed int
+		since           float64
+	)
+	for _, event := range events {
+		if down > spare {
+			unavailable += event.hour - since
+		}
+		down += event.fragments
+		destroyed += event.destroyed
+		since = event.hour
+
+		if destroyed > spare {
+			return unavailable + hours - since, true
+		}
+	}
+	return unavailable, false
+}
+
+func wilsonInterval(successes, trials int) [2]float64 {
+	var (
+		n      = float64(trials)
+		p      = float64(successes) / n
+		z2     = z95 * z95
+		denom  = 1 + z2/n
+		center = (p + z2/(2*n)) / denom
+		half   = z95 * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / denom
+	)
+	return [2]float64{max(center-half, 0), min(center+half, 1)}
+}
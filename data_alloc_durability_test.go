@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSimulateDurability(t *testing.T) {
+	single := NamedPlacement{Loads: map[string]DataCenterLoad{"fra": {Fragments: 3}}}
+	split := NamedPlacement{Loads: map[string]DataCenterLoad{"fra": {Fragments: 2}, "ams": {Fragments: 2}}}
+	yearly := FailureProfile{AnnualFailureProbability: 1 - math.Exp(-1), RepairHours: 10} // one outage a year on average
+	destructive := FailureProfile{AnnualFailureProbability: 1 - math.Exp(-1), RepairHours: 10, DestructionProbability: 1}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		config := SimulationConfig{Trials: 500, Seed: 42}
+		profiles := map[string]FailureProfile{"fra": yearly, "ams": yearly}
+		first, err := simulateDurability(split, profiles, config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		second, _ := simulateDurability(split, profiles, config)
+		if first != second {
+			t.Errorf("Expected %+v, but got %+v", first, second)
+		}
+	})
+
+	t.Run("SingleDataCenter", func(t *testing.T) {
+		report, err := simulateDurability(single, map[string]FailureProfile{"fra": yearly}, SimulationConfig{Trials: 20000, Seed: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := 1 - math.Exp(-1)
+		if expected < report.UnavailabilityInterval[0] || expected > report.UnavailabilityInterval[1] {
+			t.Errorf("Expected %.3f within %v, got %.3f", expected, report.UnavailabilityInterval, report.UnavailabilityProbability)
+		}
+		if math.Abs(report.ExpectedUnavailableHours-10) > 0.5 {
+			t.Errorf("Expected about 10 hours, but got %.2f within %v", report.ExpectedUnavailableHours, report.UnavailableInterval)
+		}
+		// outages only take the data center down, repairs bring everything back
+		if report.LossProbability != 0 {
+			t.Errorf("Expected no losses, but got %.3f", report.LossProbability)
+		}
+	})
+
+	t.Run("Destruction", func(t *testing.T) {
+		report, err := simulateDurability(single, map[string]FailureProfile{"fra": destructive}, SimulationConfig{Trials: 20000, Seed: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := 1 - math.Exp(-1)
+		if expected < report.LossInterval[0] || expected > report.LossInterval[1] {
+			t.Errorf("Expected %.3f within %v, got %.3f", expected, report.LossInterval, report.LossProbability)
+		}
+		if report.UnavailabilityProbability != report.LossProbability {
+			t.Errorf("Expected every outage to be a loss, but got %+v", report)
+		}
+		// a loss at a uniform time of the year leaves about half of it unavailable
+		if report.ExpectedUnavailableHours < 0.25*hoursPerYear*expected || report.ExpectedUnavailableHours > 0.75*hoursPerYear*expected {
+			t.Errorf("Expected months of unavailability, but got %.2f hours", report.ExpectedUnavailableHours)
+		}
+	})
+
+	t.Run("RebuildFromSurvivors", func(t *testing.T) {
+		profiles := map[string]FailureProfile{"fra": destructive, "ams": destructive}
+		report, err := simulateDurability(split, profiles, SimulationConfig{Trials: 5000, Seed: 3, Required: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// one data center destroyed is rebuilt from the other, both must go within 10 hours
+		if report.LossProbability > 0.01 || report.LossProbability > report.UnavailabilityProbability {
+			t.Errorf("Expected rare losses, but got %+v", report)
+		}
+	})
+
+	t.Run("SurvivesSingleOutage", func(t *testing.T) {
+		profiles := map[string]FailureProfile{"fra": yearly, "ams": yearly}
+		strict, _ := simulateDurability(split, profiles, SimulationConfig{Trials: 5000, Seed: 7})
+		tolerant, err := simulateDurability(split, profiles, SimulationConfig{Trials: 5000, Seed: 7, Required: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// losing one data center is fine, both must be down at once: about 2 * 10h/8760h a year
+		if tolerant.UnavailabilityProbability >= strict.UnavailabilityProbability/10 || tolerant.UnavailabilityProbability > 0.01 {
+			t.Errorf("Expected rare outages, but got %.4f against %.4f", tolerant.UnavailabilityProbability, strict.UnavailabilityProbability)
+		}
+		if strict.LossProbability != 0 || tolerant.LossProbability != 0 {
+			t.Errorf("Expected no losses without destruction, but got %+v and %+v", strict, tolerant)
+		}
+	})
+
+	t.Run("NeverFails", func(t *testing.T) {
+		report, err := simulateDurability(single, map[string]FailureProfile{"fra": {RepairHours: 10}}, SimulationConfig{Trials: 100})
+		if err != nil || report.LossProbability != 0 || report.UnavailabilityProbability != 0 || report.ExpectedUnavailableHours != 0 {
+			t.Errorf("Expected no losses, but got %+v, %v", report, err)
+		}
+	})
+
+	t.Run("InstantRepairDestroys", func(t *testing.T) {
+		profiles := map[string]FailureProfile{"fra": {AnnualFailureProbability: 0.5, DestructionProbability: 1}}
+		if _, err := simulateDurability(single, profiles, SimulationConfig{Trials: 100}); !errors.Is(err, ErrInvalidSimulation) {
+			t.Errorf("Expected %v, but got %v", ErrInvalidSimulation, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			profiles map[string]FailureProfile
+			config   SimulationConfig
+		}{
+			{map[string]FailureProfile{"fra": yearly}, SimulationConfig{}},
+			{map[string]FailureProfile{}, SimulationConfig{Trials: 10}},
+			{map[string]FailureProfile{"fra": {AnnualFailureProbability: 1}}, SimulationConfig{Trials: 10}},
+			{map[string]FailureProfile{"fra": {AnnualFailureProbability: 0.1, DestructionProbability: 1.5}}, SimulationConfig{Trials: 10}},
+			{map[string]FailureProfile{"fra": yearly}, SimulationConfig{Trials: 10, Required: 4}},
+		}
+		for _, c := range cases {
+			if _, err := simulateDurability(single, c.profiles, c.config); !errors.Is(err, ErrInvalidSimulation) {
+				t.Errorf("%+v: Expected %v, but got %v", c, ErrInvalidSimulation, err)
+			}
+		}
+	})
+}
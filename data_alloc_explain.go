@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SearchStep is a single probe of the binary search.
+type SearchStep struct {
+	Probe      int64 `json:"probe"`
+	Capacities []int `json:"capacities"` // per data center in input order
+	Capacity   int   `json:"capacity"`
+	Feasible   bool  `json:"feasible"`
+}
+
+// Explanation is a trace of distributeNamedFragments.
+type Explanation struct {
+	DataCenters []string     `json:"data_centers"`
+	Fragments   int          `json:"fragments"`
+	Steps       []SearchStep `json:"steps"`
+	MaxRisk     int64        `json:"max_risk"`
+	Bottleneck  string       `json:"bottleneck"` // data center holding MaxRisk in the placement
+	// Binding data centers lose capacity right below MaxRisk: one of their risk^count
+	// is exactly MaxRisk, so any lower bound is short of room.
+	Binding   []string `json:"binding"`
+	Placement []int    `json:"placement"` // fragments per data center in input order
+}
+
+// explainDistribution runs distributeNamedFragments recording every probe of the search.
+func explainDistribution(dataCenters []DataCenter, fragments int) (Explanation, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return Explanation{}, err
+	}
+
+	explanation := Explanation{DataCenters: make([]string, len(dataCenters)), Fragments: fragments}
+	for i, dc := range dataCenters {
+		explanation.DataCenters[i] = dc.ID
+	}
+
+	minRisk, search, err := boundedRiskFloor(dataCenters, fragments, ExponentialRisk{})
+	if err != nil {
+		return Explanation{}, err
+	}
+	var maxRisk int64
+	if search {
+		// the same check as searchBoundedRisk, capacities are recorded even below minimums
+		maxRisk, err = searchAchievableRisk(dataCenters, fragments, ExponentialRisk{}, minRisk, func(maxRisk int64) bool {
+			step := SearchStep{Probe: maxRisk, Capacities: capacitiesUnder(dataCenters, maxRisk, fragments)}
+			for _, capacity := range step.Capacities {
+				step.Capacity += capacity
+			}
+			step.Feasible = maxRisk >= minRisk && step.Capacity >= fragments
+			explanation.Steps = append(explanation.Steps, step)
+			return step.Feasible
+		})
+		if err != nil {
+			return Explanation{}, err
+		}
+	}
+
+	placement := placeFragments(dataCenters, fragments, maxRisk, ExponentialRisk{})
+	explanation.MaxRisk = placement.MaxRisk
+	explanation.Placement = make([]int, len(dataCenters))
+	for i, load := range placement.Loads {
+		explanation.Placement[i] = load.Fragments
+	}
+	if placement.Bottleneck >= 0 {
+		explanation.Bottleneck = dataCenters[placement.Bottleneck].ID
+	}
+
+	if maxRisk > 0 {
+		at, below := capacitiesUnder(dataCenters, maxRisk, fragments), capacitiesUnder(dataCenters, maxRisk-1, fragments)
+		for i := range dataCenters {
+			if at[i] > below[i] {
+				explanation.Binding = append(explanation.Binding, dataCenters[i].ID)
+			}
+		}
+	}
+	return explanation, nil
+}
+
+func capacitiesUnder(dataCenters []DataCenter, maxRisk int64, fragments int) []int {
+	capacities := make([]int, len(dataCenters))
+	for i, dc := range dataCenters {
+		capacities[i] = dc.capacity(maxRisk, fragments, ExponentialRisk{})
+	}
+	return capacities
+}
+
+// String is a human-readable trace, one probe per line.
+func (e Explanation) String() string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "distribute %d fragments over %d data centers\n", e.Fragments, len(e.DataCenters))
+	for n, step := range e.Steps {
+		verdict := "infeasible"
+		if step.Feasible {
+			verdict = "feasible"
+		}
+		fmt.Fprintf(sb, "step %d: probe %d: %s, total %d -> %s\n", n+1, step.Probe, e.perDataCenter(step.Capacities), step.Capacity, verdict)
+	}
+	fmt.Fprintf(sb, "minimal max risk %d, bottleneck %s, binding %s\n", e.MaxRisk, e.Bottleneck, strings.Join(e.Binding, ", "))
+	fmt.Fprintf(sb, "placement: %s\n", e.perDataCenter(e.Placement))
+	return sb.String()
+}
+
+// JSON is the trace for machines.
+func (e Explanation) JSON() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+func (e Explanation) perDataCenter(counts []int) string {
+	parts := make([]string, len(counts))
+	for i, count := range counts {
+		parts[i] = fmt.Sprintf("%s=%d", e.DataCenters[i], count)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExplainDistribution(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}
+	explanation, err := explainDistribution(dataCenters, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if explanation.MaxRisk != 400 || explanation.Bottleneck != "lon" {
+		t.Errorf("Expected 400 in lon, but got %d in %s", explanation.MaxRisk, explanation.Bottleneck)
+	}
+	if len(explanation.Binding) != 1 || explanation.Binding[0] != "lon" {
+		t.Errorf("Expected lon binding, but got %v", explanation.Binding)
+	}
+	if len(explanation.Steps) == 0 {
+		t.Fatal("Expected search steps")
+	}
+	for _, step := range explanation.Steps {
+		total := 0
+		for _, capacity := range step.Capacities {
+			total += capacity
+		}
+		if total != step.Capacity || step.Feasible != (step.Capacity >= 5) {
+			t.Errorf("inconsistent step %+v", step)
+		}
+		if step.Probe >= 400 != step.Feasible {
+			t.Errorf("probe %d: Expected feasible only from 400, got %v", step.Probe, step.Feasible)
+		}
+	}
+
+	text := explanation.String()
+	for _, line := range []string{"distribute 5 fragments over 3 data centers", "probe 400: fra=2 ams=1 lon=2, total 5 -> feasible",
+		"probe 399: fra=2 ams=1 lon=1, total 4 -> infeasible", "minimal max risk 400, bottleneck lon, binding lon", "placement: fra=2 ams=1 lon=2"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected %q in\n%s", line, text)
+		}
+	}
+
+	data, err := explanation.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Explanation
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.MaxRisk != 400 || len(decoded.Steps) != len(explanation.Steps) {
+		t.Errorf("Expected the trace back from JSON, but got %+v, %v", decoded, err)
+	}
+}
+
+func TestExplainDistribution_Minimum(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MinFragments: 2, MaxFragments: Unlimited}}
+	explanation, err := explainDistribution(dataCenters, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if explanation.MaxRisk != 900 || len(explanation.Binding) != 1 || explanation.Binding[0] != "ams" {
+		t.Errorf("Expected 900 bound by ams, but got %d bound by %v", explanation.MaxRisk, explanation.Binding)
+	}
+	for _, step := range explanation.Steps {
+		if step.Probe < 900 && step.Feasible {
+			t.Errorf("probe %d is below the ams minimum but feasible", step.Probe)
+		}
+	}
+}
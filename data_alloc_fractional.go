@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const ErrRiskOutOfDomain allocationError = "Error: risk is outside of the model's domain."
+
+// FractionalRiskModel is RiskModel for real-valued risk scores. The cost must not decrease
+// as count grows, and no fragments must cost nothing.
+type FractionalRiskModel interface {
+	FractionalCost(risk float64, count int) (float64, error)
+}
+
+// FractionalCost is risk^count, risk must be at least 1: below that more fragments would
+// cost less. Probabilities belong to ProbabilisticRisk.
+func (ExponentialRisk) FractionalCost(risk float64, count int) (float64, error) {
+	if !(risk >= 1) {
+		return 0, fmt.Errorf("%w: exponential risk %v is below 1", ErrRiskOutOfDomain, risk)
+	}
+	if count <= 0 {
+		return 0, nil
+	}
+	return math.Pow(risk, float64(count)), nil
+}
+
+func (LinearRisk) FractionalCost(risk float64, count int) (float64, error) {
+	if !(risk > 0) || math.IsInf(risk, 1) {
+		return 0, fmt.Errorf("%w: linear risk %v", ErrRiskOutOfDomain, risk)
+	}
+	return risk * float64(max(count, 0)), nil
+}
+
+func (QuadraticRisk) FractionalCost(risk float64, count int) (float64, error) {
+	if !(risk > 0) || math.IsInf(risk, 1) {
+		return 0, fmt.Errorf("%w: quadratic risk %v", ErrRiskOutOfDomain, risk)
+	}
+	count = max(count, 0)
+	return risk * float64(count) * float64(count), nil
+}
+
+// FractionalCost takes the failure probability itself as risk, Scale is not used.
+func (ProbabilisticRisk) FractionalCost(risk float64, count int) (float64, error) {
+	if !(risk >= 0 && risk <= 1) {
+		return 0, fmt.Errorf("%w: probability %v", ErrRiskOutOfDomain, risk)
+	}
+	if count <= 0 {
+		return 0, nil
+	}
+	return -math.Expm1(float64(count) * math.Log1p(-risk)), nil
+}
+
+// FractionalDataCenter is DataCenter with a real-valued risk score.
+type FractionalDataCenter struct {
+	ID           string
+	Risk         float64
+	MaxFragments int // storage quota, Unlimited if there is none
+	MinFragments int
+}
+
+// FractionalLoad is what landed in a single data center.
+type FractionalLoad struct {
+	Index     int
+	Risk      float64
+	Fragments int
+	Cost      float64
+}
+
+// FractionalPlacement is NamedPlacement with real-valued risks.
+type FractionalPlacement struct {
+	MaxRisk    float64
+	Loads      map[string]FractionalLoad
+	Bottleneck string
+}
+
+// distributeFractionalFragments is distributeModeledFragments for real-valued risks.
+// Instead of the integer search over [1, maxRisk] the bound is bisected in log space until
+// the interval is narrower than precision (relative, 1e-9 if not positive). The answer is
+// always one of the costs of some data center, so the costs left inside the interval are
+// then checked one by one: the result is exact, integral inputs give the integer answer,
+// and precision only trades bisection steps for candidate checks.
+func distributeFractionalFragments(dataCenters []FractionalDataCenter, fragments int, model FractionalRiskModel, precision float64) (FractionalPlacement, error) {
+	if precision <= 0 {
+		precision = 1e-9
+	}
+	fragments = max(fragments, 0)
+
+	var (
+		seen    = make(map[string]struct{}, len(dataCenters))
+		totals  quotaTotals
+		minRisk float64
+	)
+	for _, dc := range dataCenters {
+		if err := validateQuota(seen, dc.ID, dc.MinFragments, dc.MaxFragments); err != nil {
+			return FractionalPlacement{}, err
+		}
+		cost, err := model.FractionalCost(dc.Risk, dc.MinFragments)
+		if err != nil {
+			return FractionalPlacement{}, fmt.Errorf("data center %s: %w", dc.ID, err)
+		}
+		minRisk = max(minRisk, cost)
+		totals.add(dc.MinFragments, dc.MaxFragments)
+	}
+	if err := totals.check(fragments, len(dataCenters)); err != nil {
+		return FractionalPlacement{}, err
+	}
+
+	capacities := func(maxRisk float64) ([]int, bool) {
+		caps, total := make([]int, len(dataCenters)), 0
+		for i, dc := range dataCenters {
+			caps[i] = dc.capacity(model, maxRisk, fragments)
+			total += caps[i]
+		}
+		return caps, total >= fragments && maxRisk >= minRisk
+	}
+
+	// bracket: (lo, hi] holds the answer
+	lo, hi := 0.0, 0.0
+	if _, ok := capacities(0); !ok {
+		hi = math.SmallestNonzeroFloat64
+		for _, dc := range dataCenters {
+			cost, _ := model.FractionalCost(dc.Risk, 1)
+			hi = max(hi, cost, minRisk)
+		}
+		for _, ok := capacities(hi); !ok && !math.IsInf(hi, 1); _, ok = capacities(hi) {
+			lo, hi = hi, hi*2
+		}
+		if math.IsInf(hi, 1) {
+			return FractionalPlacement{}, ErrRiskOverflow
+		}
+		for step := 0; step < 2000 && hi-lo > precision*hi; step++ {
+			mid := math.Sqrt(lo * hi)
+			if lo == 0 || mid <= lo || mid >= hi {
+				mid = lo + (hi-lo)/2
+			}
+			if _, ok := capacities(mid); ok {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		hi = narrowFractional(dataCenters, model, fragments, lo, hi, capacities)
+	}
+
+	caps, _ := capacities(hi)
+	return placeFractional(dataCenters, model, fragments, caps), nil
+}
+
+// narrowFractional checks every cost within (lo, hi] in increasing order, the first feasible
+// one is the answer.
+func narrowFractional(dataCenters []FractionalDataCenter, model FractionalRiskModel, fragments int, lo, hi float64,
+	capacities func(float64) ([]int, bool)) float64 {
+	var candidates []float64
+	for _, dc := range dataCenters {
+		for count := dc.capacity(model, lo, fragments) + 1; count <= dc.capacity(model, hi, fragments); count++ {
+			cost, _ := model.FractionalCost(dc.Risk, count)
+			candidates = append(candidates, cost)
+		}
+	}
+	sort.Float64s(candidates)
+
+	for _, candidate := range candidates {
+		if _, ok := capacities(candidate); ok {
+			return candidate
+		}
+	}
+	return hi
+}
+
+func (dc FractionalDataCenter) capacity(model FractionalRiskModel, maxRisk float64, limit int) int {
+	if dc.MaxFragments != Unlimited {
+		limit = min(limit, dc.MaxFragments)
+	}
+	return sort.Search(limit, func(count int) bool {
+		cost, _ := model.FractionalCost(dc.Risk, count+1)
+		return cost > maxRisk
+	})
+}
+
+// placeFractional is placeFragments with precomputed capacities.
+func placeFractional(dataCenters []FractionalDataCenter, model FractionalRiskModel, fragments int, caps []int) FractionalPlacement {
+	risks := make([]float64, len(dataCenters))
+	for i, dc := range dataCenters {
+		risks[i] = dc.Risk
+	}
+	order := riskOrder(risks)
+
+	counts := make([]int, len(dataCenters))
+	remainingFragments := fragments
+	for i, dc := range dataCenters {
+		counts[i] = dc.MinFragments
+		remainingFragments -= dc.MinFragments
+	}
+	for _, i := range order {
+		extra := min(caps[i]-counts[i], remainingFragments)
+		if extra > 0 {
+			counts[i] += extra
+			remainingFragments -= extra
+		}
+	}
+
+	placement := FractionalPlacement{Loads: make(map[string]FractionalLoad, len(dataCenters))}
+	for i, dc := range dataCenters {
+		cost, _ := model.FractionalCost(dc.Risk, counts[i])
+		placement.Loads[dc.ID] = FractionalLoad{Index: i, Risk: dc.Risk, Fragments: counts[i], Cost: cost}
+		if counts[i] > 0 && cost > placement.MaxRisk {
+			placement.MaxRisk = cost
+			placement.Bottleneck = dc.ID
+		}
+	}
+	return placement
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDistributeFractionalFragments_MatchesIntegral(t *testing.T) {
+	inputs := [][]int{{10, 30, 20}, {10, 10, 10}, {5, 10, 7}, {1000000}, {3, 7, 11}, {1, 50}}
+	models := []interface {
+		RiskModel
+		FractionalRiskModel
+	}{ExponentialRisk{}, LinearRisk{}, QuadraticRisk{}}
+
+	for _, model := range models {
+		for _, risks := range inputs {
+			for _, fragments := range []int{1, 2, 5, 10, 30} {
+				var (
+					integral   = make([]DataCenter, len(risks))
+					fractional = make([]FractionalDataCenter, len(risks))
+				)
+				for i, risk := range risks {
+					id := string(rune('a' + i))
+					integral[i] = DataCenter{ID: id, Risk: risk, MaxFragments: Unlimited}
+					fractional[i] = FractionalDataCenter{ID: id, Risk: float64(risk), MaxFragments: Unlimited}
+				}
+
+				expected, err := distributeModeledFragments(integral, fragments, model)
+				if err != nil {
+					continue // overflows int64, nothing to compare with
+				}
+				for _, precision := range []float64{0, 0.5} {
+					placement, err := distributeFractionalFragments(fractional, fragments, model, precision)
+					if err != nil || placement.MaxRisk != float64(expected.MaxRisk) {
+						t.Errorf("%T %v %d (precision %v): Expected %d, but got %v, %v",
+							model, risks, fragments, precision, expected.MaxRisk, placement.MaxRisk, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDistributeFractionalFragments(t *testing.T) {
+	t.Run("Multipliers", func(t *testing.T) {
+		dataCenters := []FractionalDataCenter{{ID: "fra", Risk: 1.7, MaxFragments: Unlimited}, {ID: "ams", Risk: 2.5, MaxFragments: Unlimited}}
+		placement, err := distributeFractionalFragments(dataCenters, 5, ExponentialRisk{}, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 1.7^3 = 4.913 < 2.5^2 = 6.25 < 1.7^4 = 8.3521
+		if placement.MaxRisk != 6.25 || placement.Bottleneck != "ams" {
+			t.Errorf("Expected 6.25 in ams, but got %v in %s", placement.MaxRisk, placement.Bottleneck)
+		}
+		if placement.Loads["fra"].Fragments != 3 || placement.Loads["ams"].Fragments != 2 {
+			t.Errorf("Expected 3 and 2 fragments, but got %+v", placement.Loads)
+		}
+	})
+
+	t.Run("Probabilities", func(t *testing.T) {
+		dataCenters := []FractionalDataCenter{{ID: "fra", Risk: 0.0031, MaxFragments: Unlimited}, {ID: "ams", Risk: 0.01, MaxFragments: Unlimited}, {ID: "lon", Risk: 0.5, MinFragments: 1, MaxFragments: Unlimited}}
+		placement, err := distributeFractionalFragments(dataCenters, 6, ProbabilisticRisk{}, 1e-12)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.MaxRisk != 0.5 || placement.Loads["fra"].Fragments != 5 || placement.Loads["lon"].Fragments != 1 {
+			t.Errorf("Expected 5 fragments in fra and 0.5 in lon, but got %+v", placement)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			dataCenters []FractionalDataCenter
+			model       FractionalRiskModel
+			expected    error
+		}{
+			{[]FractionalDataCenter{{ID: "fra", Risk: 0.5, MaxFragments: Unlimited}}, ExponentialRisk{}, ErrRiskOutOfDomain},
+			{[]FractionalDataCenter{{ID: "fra", Risk: math.NaN(), MaxFragments: Unlimited}}, LinearRisk{}, ErrRiskOutOfDomain},
+			{[]FractionalDataCenter{{ID: "fra", Risk: 1.5, MaxFragments: Unlimited}}, ProbabilisticRisk{}, ErrRiskOutOfDomain},
+			{[]FractionalDataCenter{{ID: "fra", Risk: 2, MaxFragments: 1}}, ExponentialRisk{}, ErrInsufficientCapacity},
+			{[]FractionalDataCenter{{ID: "fra", Risk: 1e300, MaxFragments: Unlimited}}, ExponentialRisk{}, ErrRiskOverflow},
+			{nil, ExponentialRisk{}, ErrNoDataCenters},
+		}
+		for _, c := range cases {
+			if _, err := distributeFractionalFragments(c.dataCenters, 3, c.model, 0); !errors.Is(err, c.expected) {
+				t.Errorf("%+v: Expected %v, but got %v", c.dataCenters, c.expected, err)
+			}
+		}
+	})
+}
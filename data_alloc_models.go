@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// RiskModel is the cost of keeping count fragments in a data center with the given risk score.
+// Cost must not decrease as count grows, and no fragments must cost nothing.
+type RiskModel interface {
+	// Cost of count fragments, false if it overflows int64.
+	Cost(risk, count int) (int64, bool)
+	// Capacity is the max count <= limit with Cost(risk, count) <= maxRisk.
+	Capacity(risk int, maxRisk int64, limit int) int
+}
+
+// ExponentialRisk is risk^count, the default model of distributeFragments.
+type ExponentialRisk struct{}
+
+func (ExponentialRisk) Cost(risk, count int) (int64, bool) {
+	if count <= 0 {
+		return 0, true
+	}
+	return checkedPow(risk, count)
+}
+
+func (ExponentialRisk) Capacity(risk int, maxRisk int64, limit int) int {
+	return fragmentsUnderRisk(risk, maxRisk, limit)
+}
+
+// LinearRisk is risk*count.
+type LinearRisk struct{}
+
+func (LinearRisk) Cost(risk, count int) (int64, bool) {
+	if count <= 0 {
+		return 0, true
+	}
+	if int64(count) > math.MaxInt64/int64(risk) {
+		return 0, false
+	}
+	return int64(risk) * int64(count), true
+}
+
+func (LinearRisk) Capacity(risk int, maxRisk int64, limit int) int {
+	if maxRisk <= 0 || limit <= 0 {
+		return 0
+	}
+	return int(min(maxRisk/int64(risk), int64(limit)))
+}
+
+// QuadraticRisk is risk*count^2.
+type QuadraticRisk struct{}
+
+func (QuadraticRisk) Cost(risk, count int) (int64, bool) {
+	if count <= 0 {
+		return 0, true
+	}
+	square, ok := checkedPow(count, 2)
+	if !ok || square > math.MaxInt64/int64(risk) {
+		return 0, false
+	}
+	return int64(risk) * square, true
+}
+
+func (m QuadraticRisk) Capacity(risk int, maxRisk int64, limit int) int {
+	return searchCapacity(m, risk, maxRisk, limit)
+}
+
+// ProbabilisticRisk is the probability to lose at least one of count fragments,
+// 1-(1-p)^count, where risk is the failure probability p in 1/Scale units.
+// The cost is in 1/Scale units as well, rounded to the nearest unit.
+type ProbabilisticRisk struct {
+	Scale int64 // 1000000 (parts per million) if zero
+}
+
+func (m ProbabilisticRisk) scale() int64 {
+	if m.Scale <= 0 {
+		return 1000000
+	}
+	return m.Scale
+}
+
+func (m ProbabilisticRisk) Cost(risk, count int) (int64, bool) {
+	if count <= 0 {
+		return 0, true
+	}
+	scale := m.scale()
+	p := min(float64(risk)/float64(scale), 1) // risk above scale is a certain failure
+	loss := -math.Expm1(float64(count) * math.Log1p(-p))
+	return min(int64(math.Round(loss*float64(scale))), scale), true
+}
+
+func (m ProbabilisticRisk) Capacity(risk int, maxRisk int64, limit int) int {
+	return searchCapacity(m, risk, maxRisk, limit)
+}
+
+// searchCapacity is a generic Capacity: binary search for the last count whose cost fits.
+func searchCapacity(model RiskModel, risk int, maxRisk int64, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return sort.Search(limit, func(count int) bool {
+		cost, ok := model.Cost(risk, count+1)
+		return !ok || cost > maxRisk
+	})
+}
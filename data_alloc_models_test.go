@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRiskModels_CapacityMatchesCost(t *testing.T) {
+	models := map[string]RiskModel{
+		"Exponential":   ExponentialRisk{},
+		"Linear":        LinearRisk{},
+		"Quadratic":     QuadraticRisk{},
+		"Probabilistic": ProbabilisticRisk{},
+	}
+	risks := []int{1, 2, 7, 10, 1000, 250000}
+	bounds := []int64{0, 1, 9, 10, 99, 100, 1000, 123456, 999999, 1000000, math.MaxInt64}
+
+	for name, model := range models {
+		t.Run(name, func(t *testing.T) {
+			for _, risk := range risks {
+				for _, bound := range bounds {
+					expected := 0
+					for count := 1; count <= 50; count++ {
+						cost, ok := model.Cost(risk, count)
+						if !ok || cost > bound {
+							break
+						}
+						expected = count
+					}
+					if result := model.Capacity(risk, bound, 50); result != expected {
+						t.Errorf("risk %d, bound %d: Expected %d, but got %d", risk, bound, expected, result)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeModeledFragments(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}
+	tests := []struct {
+		name       string
+		model      RiskModel
+		maxRisk    int64
+		bottleneck string
+		expected   map[string]int
+	}{
+		{"Exponential", ExponentialRisk{}, 400, "lon", map[string]int{"fra": 2, "ams": 1, "lon": 2}},
+		{"Linear", LinearRisk{}, 30, "fra", map[string]int{"fra": 3, "ams": 1, "lon": 1}},
+		{"Quadratic", QuadraticRisk{}, 80, "lon", map[string]int{"fra": 2, "ams": 1, "lon": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := distributeModeledFragments(dataCenters, 5, tt.model)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk || placement.Bottleneck != tt.bottleneck {
+				t.Errorf("Expected %d in %s, but got %d in %s", tt.maxRisk, tt.bottleneck, placement.MaxRisk, placement.Bottleneck)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+		})
+	}
+
+	t.Run("Probabilistic", func(t *testing.T) {
+		// failure probabilities in ppm: 0.1%, 0.5% and 1%
+		dataCenters := []DataCenter{{ID: "fra", Risk: 1000, MaxFragments: Unlimited}, {ID: "ams", Risk: 5000, MaxFragments: Unlimited}, {ID: "lon", Risk: 10000, MaxFragments: Unlimited}}
+		placement, err := distributeModeledFragments(dataCenters, 12, ProbabilisticRisk{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 1-(1-0.005)^2 = 9975ppm beats 1% of a single fragment in lon
+		if placement.MaxRisk != 9975 || placement.Bottleneck != "ams" {
+			t.Errorf("Expected 9975 in ams, but got %d in %s", placement.MaxRisk, placement.Bottleneck)
+		}
+		if placement.Loads["fra"].Fragments != 10 || placement.Loads["ams"].Fragments != 2 {
+			t.Errorf("Expected 10 fragments in fra and 2 in ams, but got %+v", placement.Loads)
+		}
+	})
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	ErrInvalidDataCenterID     allocationError = "Error: data center IDs must be unique and non-empty."
+	ErrInvalidCapacity         allocationError = "Error: data center capacity must satisfy 0 <= MinFragments <= MaxFragments."
+	ErrInsufficientCapacity    allocationError = "Error: data centers can't hold all fragments."
+	ErrMinimumExceedsFragments allocationError = "Error: minimum fragments exceed fragments to place."
+)
+
+// Unlimited is the MaxFragments of a data center without a storage quota.
+const Unlimited = -1
+
+// DataCenter is a data center known by ID rather than by its position in a slice.
+type DataCenter struct {
+	ID           string
+	Risk         int
+	MaxFragments int // storage quota, Unlimited if there is none, 0 if it is used up
+	MinFragments int // fragments the data center must hold anyway
+}
+
+// NamedPlacement is Placement keyed by data center ID.
+type NamedPlacement struct {
+	MaxRisk    int64
+	Loads      map[string]DataCenterLoad // Index is the position in the input slice
+	Bottleneck string                    // empty if nothing is placed
+}
+
+// distributeNamedFragments is distributeFragmentsPlan for named data centers.
+// The input is never modified, results are keyed by data center ID.
+// Every data center gets at least MinFragments and at most MaxFragments fragments.
+func distributeNamedFragments(dataCenters []DataCenter, fragments int) (NamedPlacement, error) {
+	return distributeModeledFragments(dataCenters, fragments, ExponentialRisk{})
+}
+
+// distributeModeledFragments is distributeNamedFragments with costs from the given model.
+func distributeModeledFragments(dataCenters []DataCenter, fragments int, model RiskModel) (NamedPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return NamedPlacement{}, err
+	}
+
+	maxRisk, err := minimalBoundedRisk(dataCenters, fragments, model)
+	if err != nil {
+		return NamedPlacement{}, err
+	}
+	return namePlacement(dataCenters, placeFragments(dataCenters, fragments, maxRisk, model)), nil
+}
+
+func validateDataCenters(dataCenters []DataCenter) error {
+	seen := make(map[string]struct{}, len(dataCenters))
+	for _, dc := range dataCenters {
+		if err := validateQuota(seen, dc.ID, dc.MinFragments, dc.MaxFragments); err != nil {
+			return err
+		}
+		if dc.Risk <= 0 {
+			return fmt.Errorf("%w: data center %s has risk %d", ErrNonPositiveRisk, dc.ID, dc.Risk)
+		}
+	}
+	return nil
+}
+
+// validateQuota checks what data centers share whatever their risk is: a unique non-empty
+// ID and 0 <= minFragments <= maxFragments, unless maxFragments is Unlimited.
+func validateQuota(seen map[string]struct{}, id string, minFragments, maxFragments int) error {
+	if _, ok := seen[id]; ok || id == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidDataCenterID, id)
+	}
+	seen[id] = struct{}{}
+
+	if minFragments < 0 || maxFragments < Unlimited || (maxFragments != Unlimited && minFragments > maxFragments) {
+		return fmt.Errorf("%w: data center %s has [%d, %d]", ErrInvalidCapacity, id, minFragments, maxFragments)
+	}
+	return nil
+}
+
+// quotaTotals sums minimums and quotas of the data centers a placement must honor.
+type quotaTotals struct {
+	required  int
+	quota     int
+	unlimited bool
+}
+
+func (t *quotaTotals) add(minFragments, maxFragments int) {
+	t.required += minFragments
+	if maxFragments == Unlimited {
+		t.unlimited = true
+	} else {
+		t.quota += maxFragments
+	}
+}
+
+// check rejects what no bound can fix: minimums above fragments, nowhere to put them,
+// quotas below fragments.
+func (t quotaTotals) check(fragments, dataCenters int) error {
+	switch {
+	case t.required > max(fragments, 0):
+		return fmt.Errorf("%w: %d required, %d to place", ErrMinimumExceedsFragments, t.required, fragments)
+	case fragments > 0 && dataCenters == 0:
+		return ErrNoDataCenters
+	case fragments > 0 && !t.unlimited && t.quota < fragments:
+		return fmt.Errorf("%w: quota %d, %d to place", ErrInsufficientCapacity, t.quota, fragments)
+	}
+	return nil
+}
+
+// minimalBoundedRisk is the minimal max risk honoring data center quotas.
+func minimalBoundedRisk(dataCenters []DataCenter, fragments int, model RiskModel) (int64, error) {
+	return searchBoundedRisk(dataCenters, fragments, model, func(maxRisk int64) int {
+		return boundedCapacity(dataCenters, maxRisk, fragments, model)
+	})
+}
+
+// searchBoundedRisk is minimalBoundedRisk with the total capacity under a bound computed by
+// capacity, which only has to be exact up to fragments.
+func searchBoundedRisk(dataCenters []DataCenter, fragments int, model RiskModel, capacity func(int64) int) (int64, error) {
+	minRisk, search, err := boundedRiskFloor(dataCenters, fragments, model)
+	if !search {
+		return 0, err
+	}
+	return searchAchievableRisk(dataCenters, fragments, model, minRisk, func(maxRisk int64) bool {
+		return maxRisk >= minRisk && capacity(maxRisk) >= fragments
+	})
+}
+
+// boundedRiskFloor checks what a bounded search gets and returns the lowest bound that
+// minimums allow. search is false if there is nothing to search: err or no fragments.
+func boundedRiskFloor(dataCenters []DataCenter, fragments int, model RiskModel) (minRisk int64, search bool, err error) {
+	var totals quotaTotals
+	for _, dc := range dataCenters {
+		totals.add(dc.MinFragments, dc.MaxFragments)
+		if dc.MinFragments > 0 { // the lowest bound these fragments allow
+			risk, ok := model.Cost(dc.Risk, dc.MinFragments)
+			if !ok {
+				return 0, false, fmt.Errorf("%w: data center %s minimum", ErrRiskOverflow, dc.ID)
+			}
+			minRisk = max(minRisk, risk)
+		}
+	}
+
+	if err := totals.check(fragments, len(dataCenters)); err != nil {
+		return 0, false, err
+	}
+	return minRisk, fragments > 0, nil
+}
+
+// searchAchievableRisk is the minimal bound from minRisk up that achievable accepts.
+func searchAchievableRisk(dataCenters []DataCenter, fragments int, model RiskModel, minRisk int64, achievable func(int64) bool) (int64, error) {
+	maxRisk := int64(math.MaxInt64)
+	if bound, ok := upperRiskBound(sortedRisks(dataCenters), fragments, model); ok && achievable(bound) {
+		maxRisk = bound // quotas may break the even spread estimation, then the search is just longer
+	}
+	return searchMinimalRisk(minRisk, maxRisk, achievable)
+}
+
+// boundedCapacity sums capacities under maxRisk, stops counting once fragments fit.
+func boundedCapacity(dataCenters []DataCenter, maxRisk int64, fragments int, model RiskModel) int {
+	total := 0
+	for _, dc := range dataCenters {
+		total += dc.capacity(maxRisk, fragments-total, model)
+		if total >= fragments {
+			break
+		}
+	}
+	return total
+}
+
+// capacity is how many fragments (up to limit) the data center takes without exceeding maxRisk.
+func (dc DataCenter) capacity(maxRisk int64, limit int, model RiskModel) int {
+	if dc.MaxFragments != Unlimited {
+		limit = min(limit, dc.MaxFragments)
+	}
+	return model.Capacity(dc.Risk, maxRisk, limit)
+}
+
+// placeFragments places minimums first, the rest goes greedily into the least risky
+// data centers under maxRisk. Equal risks are filled in input order.
+func placeFragments(dataCenters []DataCenter, fragments int, maxRisk int64, model RiskModel) Placement {
+	return placeSpreadFragments(dataCenters, fragments, 0, maxRisk, model)
+}
+
+// placeSpreadFragments is placeFragments where, after minimums, the least risky empty data
+// centers get one fragment each until at least spread data centers hold fragments.
+func placeSpreadFragments(dataCenters []DataCenter, fragments, spread int, maxRisk int64, model RiskModel) Placement {
+	placement := Placement{Loads: make([]DataCenterLoad, len(dataCenters))}
+	order := riskOrder(risksOf(dataCenters))
+
+	remainingFragments, used := fragments, 0
+	for i, dc := range dataCenters {
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: dc.MinFragments}
+		remainingFragments -= dc.MinFragments
+		if dc.MinFragments > 0 {
+			used++
+		}
+	}
+
+	for _, i := range order {
+		if used >= spread || remainingFragments <= 0 {
+			break
+		}
+		if load := &placement.Loads[i]; load.Fragments == 0 && dataCenters[i].capacity(maxRisk, 1, model) == 1 {
+			load.Fragments = 1
+			remainingFragments--
+			used++
+		}
+	}
+
+	for _, i := range order {
+		if remainingFragments <= 0 {
+			break
+		}
+		load := &placement.Loads[i]
+		extra := dataCenters[i].capacity(maxRisk, load.Fragments+remainingFragments, model) - load.Fragments
+		if extra > 0 {
+			load.Fragments += extra
+			remainingFragments -= extra
+		}
+	}
+
+	priceLoads(&placement, model)
+	return placement
+}
+
+// priceLoads fills costs of every load, the max risk and the bottleneck.
+func priceLoads(placement *Placement, model RiskModel) {
+	placement.MaxRisk, placement.Bottleneck = 0, -1
+	for i := range placement.Loads {
+		load := &placement.Loads[i]
+		load.Cost, _ = model.Cost(load.Risk, load.Fragments) // fits, it is under maxRisk
+		if load.Fragments > 0 && load.Cost > placement.MaxRisk {
+			placement.MaxRisk = load.Cost
+			placement.Bottleneck = i
+		}
+	}
+}
+
+func risksOf(dataCenters []DataCenter) []int {
+	risks := make([]int, len(dataCenters))
+	for i, dc := range dataCenters {
+		risks[i] = dc.Risk
+	}
+	return risks
+}
+
+func sortedRisks(dataCenters []DataCenter) []int {
+	risks := risksOf(dataCenters)
+	sort.Ints(risks)
+	return risks
+}
+
+// anonymousDataCenters turns plain risks into unlimited data centers.
+func anonymousDataCenters(risks []int) []DataCenter {
+	dataCenters := make([]DataCenter, len(risks))
+	for i, risk := range risks {
+		dataCenters[i] = DataCenter{Risk: risk, MaxFragments: Unlimited}
+	}
+	return dataCenters
+}
+
+func namePlacement(dataCenters []DataCenter, placement Placement) NamedPlacement {
+	named := NamedPlacement{
+		MaxRisk: placement.MaxRisk,
+		Loads:   make(map[string]DataCenterLoad, len(dataCenters)),
+	}
+	for i, load := range placement.Loads {
+		named.Loads[dataCenters[i].ID] = load
+	}
+	if placement.Bottleneck >= 0 {
+		named.Bottleneck = dataCenters[placement.Bottleneck].ID
+	}
+	return named
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistributeNamedFragments(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}
+		placement, err := distributeNamedFragments(dataCenters, 5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.MaxRisk != 400 || placement.Bottleneck != "lon" {
+			t.Errorf("Expected 400 in lon, but got %d in %s", placement.MaxRisk, placement.Bottleneck)
+		}
+		expected := map[string]int{"fra": 2, "ams": 1, "lon": 2}
+		for id, count := range expected {
+			if placement.Loads[id].Fragments != count {
+				t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+			}
+		}
+		if dataCenters[0].ID != "fra" || dataCenters[1].ID != "ams" || dataCenters[2].ID != "lon" {
+			t.Errorf("input must not be reordered, got %v", dataCenters)
+		}
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		_, err := distributeNamedFragments([]DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "fra", Risk: 20, MaxFragments: Unlimited}}, 2)
+		if !errors.Is(err, ErrInvalidDataCenterID) {
+			t.Errorf("Expected %v, but got %v", ErrInvalidDataCenterID, err)
+		}
+	})
+
+	t.Run("EmptyID", func(t *testing.T) {
+		_, err := distributeNamedFragments([]DataCenter{{ID: "", Risk: 10, MaxFragments: Unlimited}}, 2)
+		if !errors.Is(err, ErrInvalidDataCenterID) {
+			t.Errorf("Expected %v, but got %v", ErrInvalidDataCenterID, err)
+		}
+	})
+
+	t.Run("NonPositiveRisk", func(t *testing.T) {
+		_, err := distributeNamedFragments([]DataCenter{{ID: "fra", Risk: 0, MaxFragments: Unlimited}}, 2)
+		if !errors.Is(err, ErrNonPositiveRisk) {
+			t.Errorf("Expected %v, but got %v", ErrNonPositiveRisk, err)
+		}
+	})
+}
+
+func TestDistributeNamedFragments_Capacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataCenters []DataCenter
+		fragments   int
+		maxRisk     int64
+		expected    map[string]int
+	}{
+		{
+			name: "MaxFragments",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 10, MaxFragments: 1},
+				{ID: "ams", Risk: 30, MaxFragments: Unlimited},
+				{ID: "lon", Risk: 20, MaxFragments: Unlimited},
+			},
+			fragments: 5,
+			maxRisk:   900,
+			expected:  map[string]int{"fra": 1, "ams": 2, "lon": 2},
+		},
+		{
+			name: "MinFragments",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 10, MaxFragments: Unlimited},
+				{ID: "ams", Risk: 30, MinFragments: 2, MaxFragments: Unlimited},
+			},
+			fragments: 3,
+			maxRisk:   900,
+			expected:  map[string]int{"fra": 1, "ams": 2},
+		},
+		{
+			name: "ExactQuota",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 2, MinFragments: 1, MaxFragments: 1},
+				{ID: "ams", Risk: 3, MaxFragments: 2},
+			},
+			fragments: 3,
+			maxRisk:   9,
+			expected:  map[string]int{"fra": 1, "ams": 2},
+		},
+		{
+			name: "QuotaUsedUp",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 2, MaxFragments: 0},
+				{ID: "ams", Risk: 3, MaxFragments: Unlimited},
+			},
+			fragments: 3,
+			maxRisk:   27,
+			expected:  map[string]int{"fra": 0, "ams": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := distributeNamedFragments(tt.dataCenters, tt.fragments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk {
+				t.Errorf("Expected %d, but got %d", tt.maxRisk, placement.MaxRisk)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeNamedFragments_CapacityErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataCenters []DataCenter
+		fragments   int
+		expected    error
+	}{
+		{
+			name:        "InsufficientCapacity",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: 2}, {ID: "ams", Risk: 20, MaxFragments: 2}},
+			fragments:   5,
+			expected:    ErrInsufficientCapacity,
+		},
+		{
+			name:        "MinimumExceedsFragments",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MinFragments: 3, MaxFragments: Unlimited}, {ID: "ams", Risk: 20, MinFragments: 3, MaxFragments: Unlimited}},
+			fragments:   5,
+			expected:    ErrMinimumExceedsFragments,
+		},
+		{
+			name:        "MinAboveMax",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MinFragments: 3, MaxFragments: 2}},
+			fragments:   5,
+			expected:    ErrInvalidCapacity,
+		},
+		{
+			name:        "NegativeMax",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: -2}},
+			fragments:   5,
+			expected:    ErrInvalidCapacity,
+		},
+		{
+			name:        "QuotasUsedUp",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: 0}, {ID: "ams", Risk: 20, MaxFragments: 0}},
+			fragments:   1,
+			expected:    ErrInsufficientCapacity,
+		},
+		{
+			name:        "MinimumOverflow",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 1000, MinFragments: 10, MaxFragments: Unlimited}},
+			fragments:   10,
+			expected:    ErrRiskOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := distributeNamedFragments(tt.dataCenters, tt.fragments)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+const (
+	ErrFragmentPlaced   allocationError = "Error: fragment is already placed."
+	ErrFragmentNotFound allocationError = "Error: fragment is not placed."
+)
+
+// Allocator places fragments one by one as uploads arrive. It is safe for concurrent use.
+// MinFragments can't be promised to a stream of uploads and is rejected by NewAllocator,
+// MaxFragments is honored.
+type Allocator struct {
+	mu          sync.Mutex
+	dataCenters []DataCenter
+	model       RiskModel
+	order       []int // data center indexes by risk, equal risks in input order
+	counts      []int
+	fragments   map[string]int // fragment ID -> data center index
+}
+
+// NewAllocator creates an empty allocator, nil model means ExponentialRisk.
+func NewAllocator(dataCenters []DataCenter, model RiskModel) (*Allocator, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return nil, err
+	}
+	if model == nil {
+		model = ExponentialRisk{}
+	}
+
+	for _, dc := range dataCenters {
+		if dc.MinFragments > 0 {
+			return nil, fmt.Errorf("%w: data center %s has minimum %d, online placement has no minimums", ErrInvalidCapacity, dc.ID, dc.MinFragments)
+		}
+	}
+	online := slices.Clone(dataCenters)
+	return &Allocator{
+		dataCenters: online,
+		model:       model,
+		order:       riskOrder(risksOf(online)),
+		counts:      make([]int, len(dataCenters)),
+		fragments:   make(map[string]int),
+	}, nil
+}
+
+// Place puts the fragment into the data center where it costs the least, which keeps the max
+// risk lowest. Equal costs prefer lower risk, then input order. Returns the data center ID.
+// Without removals this is the offline optimum: it picks the smallest next risk every time.
+func (a *Allocator) Place(fragment string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.fragments[fragment]; ok {
+		return "", fmt.Errorf("%w: %s", ErrFragmentPlaced, fragment)
+	}
+
+	best, bestCost, overflow := -1, int64(0), false
+	for _, i := range a.order {
+		dc := a.dataCenters[i]
+		if dc.MaxFragments != Unlimited && a.counts[i] >= dc.MaxFragments {
+			continue
+		}
+		cost, ok := a.model.Cost(dc.Risk, a.counts[i]+1)
+		if !ok {
+			overflow = true
+			continue
+		}
+		if best < 0 || cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+
+	switch {
+	case best >= 0:
+	case overflow:
+		return "", ErrRiskOverflow
+	case len(a.dataCenters) == 0:
+		return "", ErrNoDataCenters
+	default:
+		return "", fmt.Errorf("%w: %d placed", ErrInsufficientCapacity, len(a.fragments))
+	}
+
+	a.counts[best]++
+	a.fragments[fragment] = best
+	return a.dataCenters[best].ID, nil
+}
+
+// Remove frees the fragment's slot, nothing else moves.
+func (a *Allocator) Remove(fragment string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, ok := a.fragments[fragment]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrFragmentNotFound, fragment)
+	}
+	a.counts[i]--
+	delete(a.fragments, fragment)
+	return nil
+}
+
+// Placement is the current online placement.
+func (a *Allocator) Placement() NamedPlacement {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.placement()
+}
+
+// Gap compares the current max risk with the offline optimum for the same number of
+// fragments. Removals are the only way for online to fall behind.
+func (a *Allocator) Gap() (online, offline int64, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	offline, err = minimalBoundedRisk(a.dataCenters, len(a.fragments), a.model)
+	if err != nil {
+		return 0, 0, err
+	}
+	return a.placement().MaxRisk, offline, nil
+}
+
+func (a *Allocator) placement() NamedPlacement {
+	placement := Placement{Loads: make([]DataCenterLoad, len(a.dataCenters))}
+	for i, dc := range a.dataCenters {
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: a.counts[i]}
+	}
+	priceLoads(&placement, a.model)
+	return namePlacement(a.dataCenters, placement)
+}
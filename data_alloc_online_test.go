@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	allocator, err := NewAllocator([]DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"fra", "lon", "ams", "fra", "lon"}
+	for i, id := range expected {
+		result, err := allocator.Place(fmt.Sprint("f", i))
+		if err != nil || result != id {
+			t.Errorf("fragment %d: Expected %s, but got %s, %v", i, id, result, err)
+		}
+	}
+
+	online, offline, err := allocator.Gap()
+	if err != nil || online != 400 || offline != 400 {
+		t.Errorf("Expected 400 and 400, but got %d and %d, %v", online, offline, err)
+	}
+
+	for _, fragment := range []string{"f0", "f3"} {
+		if err := allocator.Remove(fragment); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	online, offline, err = allocator.Gap()
+	if err != nil || online != 400 || offline != 30 {
+		t.Errorf("Expected 400 and 30, but got %d and %d, %v", online, offline, err)
+	}
+	if placement := allocator.Placement(); placement.Loads["fra"].Fragments != 0 || placement.Bottleneck != "lon" {
+		t.Errorf("Expected empty fra and lon bottleneck, but got %+v", placement)
+	}
+
+	if _, err := allocator.Place("f1"); !errors.Is(err, ErrFragmentPlaced) {
+		t.Errorf("Expected %v, but got %v", ErrFragmentPlaced, err)
+	}
+	if err := allocator.Remove("f0"); !errors.Is(err, ErrFragmentNotFound) {
+		t.Errorf("Expected %v, but got %v", ErrFragmentNotFound, err)
+	}
+}
+
+func TestAllocator_Capacity(t *testing.T) {
+	allocator, _ := NewAllocator([]DataCenter{{ID: "fra", Risk: 2, MaxFragments: 1}, {ID: "ams", Risk: 100, MaxFragments: 1}}, LinearRisk{})
+	for i, id := range []string{"fra", "ams"} {
+		if result, err := allocator.Place(fmt.Sprint("f", i)); err != nil || result != id {
+			t.Errorf("Expected %s, but got %s, %v", id, result, err)
+		}
+	}
+	if _, err := allocator.Place("f2"); !errors.Is(err, ErrInsufficientCapacity) {
+		t.Errorf("Expected %v, but got %v", ErrInsufficientCapacity, err)
+	}
+}
+
+func TestAllocator_MinFragments(t *testing.T) {
+	if _, err := NewAllocator([]DataCenter{{ID: "fra", Risk: 10, MinFragments: 1, MaxFragments: Unlimited}}, nil); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected %v, but got %v", ErrInvalidCapacity, err)
+	}
+}
+
+func TestAllocator_Concurrent(t *testing.T) {
+	allocator, _ := NewAllocator([]DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}, {ID: "lon", Risk: 5, MaxFragments: Unlimited}}, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := allocator.Place(fmt.Sprint("f", i)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	online, offline, err := allocator.Gap()
+	if err != nil || online != offline {
+		t.Errorf("Expected online %d to match offline %d, %v", online, offline, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// distributeFragmentsParallel is distributeNamedFragments for huge inventories: every
+// feasibility check of the binary search is split over a bounded pool of workers (0 means
+// GOMAXPROCS) and stops as soon as the summed capacity reaches fragments.
+// A canceled ctx stops the search with ctx.Err(), the search skips sorting the inventory
+// for a tight upper bound so that nothing long runs unchecked.
+func distributeFragmentsParallel(ctx context.Context, dataCenters []DataCenter, fragments, workers int) (NamedPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return NamedPlacement{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return NamedPlacement{}, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	minRisk, search, err := boundedRiskFloor(dataCenters, fragments, ExponentialRisk{})
+	if !search {
+		return NamedPlacement{}, err
+	}
+	maxRisk, err := searchMinimalRisk(minRisk, evenSpreadBound(dataCenters, fragments), func(maxRisk int64) bool {
+		if ctx.Err() != nil {
+			return true // done, let the search run out without checks
+		}
+		return maxRisk >= minRisk && parallelCapacity(ctx, dataCenters, maxRisk, fragments, workers) >= fragments
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil { // canceled checks look infeasible, the result is garbage
+		return NamedPlacement{}, ctxErr
+	}
+	if err != nil {
+		return NamedPlacement{}, err
+	}
+	return namePlacement(dataCenters, placeFragments(dataCenters, fragments, maxRisk, ExponentialRisk{})), nil
+}
+
+// evenSpreadBound is the risk of spreading fragments evenly with every data center as risky
+// as the worst one. Quotas may break it, then it is math.MaxInt64 and the search is longer.
+func evenSpreadBound(dataCenters []DataCenter, fragments int) int64 {
+	worst := 0
+	for _, dc := range dataCenters {
+		if dc.MaxFragments != Unlimited {
+			return math.MaxInt64
+		}
+		worst = max(worst, dc.Risk)
+	}
+	bound, ok := ExponentialRisk{}.Cost(worst, (fragments+len(dataCenters)-1)/len(dataCenters))
+	if !ok {
+		return math.MaxInt64
+	}
+	return bound
+}
+
+// parallelCapacity is boundedCapacity over chunks of data centers handed out to workers.
+// It returns 0 once ctx is done.
+func parallelCapacity(ctx context.Context, dataCenters []DataCenter, maxRisk int64, fragments, workers int) int {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		chunkSize = max(len(dataCenters)/(workers*4), 1) // a few chunks per worker evens out slow ones
+		chunks    = make(chan int)
+		total     atomic.Int64
+		wg        sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				if ctx.Err() != nil {
+					continue // drain, the feeder stops on its own
+				}
+				end := min(start+chunkSize, len(dataCenters))
+				found := boundedCapacity(dataCenters[start:end], maxRisk, fragments, ExponentialRisk{})
+				if total.Add(int64(found)) >= int64(fragments) {
+					cancel() // enough capacity, nobody needs the rest
+				}
+			}
+		}()
+	}
+
+feed:
+	for start := 0; start < len(dataCenters); start += chunkSize {
+		if ctx.Err() != nil { // select picks at random when a worker is ready too
+			break
+		}
+		select {
+		case chunks <- start:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if total.Load() < int64(fragments) && ctx.Err() != nil {
+		return 0
+	}
+	return int(min(total.Load(), int64(fragments)))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func hugeInventory(n int) []DataCenter {
+	dataCenters := make([]DataCenter, n)
+	for i := range dataCenters {
+		dataCenters[i] = DataCenter{ID: strconv.Itoa(i), Risk: 2 + (i*7919)%97, MaxFragments: Unlimited}
+	}
+	return dataCenters
+}
+
+func TestDistributeFragmentsParallel(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataCenters []DataCenter
+		fragments   int
+	}{
+		{"Simple", []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}, 5},
+		{"Quotas", []DataCenter{{ID: "fra", Risk: 10, MaxFragments: 1}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MinFragments: 3, MaxFragments: Unlimited}}, 6},
+		{"Huge", hugeInventory(5000), 60000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := distributeNamedFragments(tt.dataCenters, tt.fragments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, workers := range []int{0, 1, 3, 16} {
+				placement, err := distributeFragmentsParallel(context.Background(), tt.dataCenters, tt.fragments, workers)
+				if err != nil || placement.MaxRisk != expected.MaxRisk {
+					t.Errorf("%d workers: Expected %d, but got %d, %v", workers, expected.MaxRisk, placement.MaxRisk, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeFragmentsParallel_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := distributeFragmentsParallel(ctx, hugeInventory(100), 1000, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, but got %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	_, err = distributeFragmentsParallel(ctx, hugeInventory(100), 1000, 4)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, but got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestDistributeFragmentsParallel_CanceledMidSearch(t *testing.T) {
+	dataCenters := hugeInventory(1000000) // about a second of search
+	goroutines := runtime.NumGoroutine()
+
+	// validation can't be canceled, cancel once the search is well under way
+	start := time.Now()
+	if err := validateDataCenters(dataCenters); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delay := time.Since(start) + 50*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	canceled := make(chan time.Time, 1)
+	time.AfterFunc(delay, func() {
+		canceled <- time.Now()
+		cancel()
+	})
+
+	start = time.Now()
+	_, err := distributeFragmentsParallel(ctx, dataCenters, 2000000, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, but got %v", context.Canceled, err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Fatalf("Expected the search to run until canceled, but it took %v", elapsed)
+	}
+	if latency := time.Since(<-canceled); latency > 100*time.Millisecond {
+		t.Errorf("Expected to return soon after cancel, but it took %v", latency)
+	}
+
+	// workers of the canceled check must be gone
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > goroutines; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d goroutines, but got %d", goroutines, runtime.NumGoroutine())
+		}
+	}
+}
+
+func TestParallelCapacity_Canceled(t *testing.T) {
+	// short of fragments, so a canceled check can't pass for a finished one
+	dataCenters := hugeInventory(1000)
+	expected := boundedCapacity(dataCenters, 100, 1000000, ExponentialRisk{})
+	if capacity := parallelCapacity(context.Background(), dataCenters, 100, 1000000, 4); capacity != expected {
+		t.Errorf("Expected %d, but got %d", expected, capacity)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if capacity := parallelCapacity(ctx, dataCenters, 100, 1000000, 4); capacity != 0 {
+		t.Errorf("Expected 0, but got %d", capacity)
+	}
+}
+
+// racks modeled as data centers: 50k of them and 1M fragments
+func BenchmarkDistributeFragmentsParallel(b *testing.B) {
+	dataCenters := hugeInventory(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = distributeFragmentsParallel(context.Background(), dataCenters, 1000000, 0)
+	}
+}
+
+func BenchmarkDistributeFragmentsSequential(b *testing.B) {
+	dataCenters := hugeInventory(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = distributeNamedFragments(dataCenters, 1000000)
+	}
+}
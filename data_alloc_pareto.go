@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const ErrNegativeAttribute allocationError = "Error: data center price, latency and objective weights must not be negative."
+
+// PricedDataCenter is a data center with a storage price and an access latency.
+type PricedDataCenter struct {
+	DataCenter
+	Price   float64 // per stored fragment
+	Latency float64
+}
+
+// ParetoPoint is a placement no other placement beats in risk, price and latency at once.
+type ParetoPoint struct {
+	MaxRisk   int64
+	Cost      float64 // total storage price
+	Latency   float64 // slowest data center holding fragments, reads need all of them
+	Placement NamedPlacement
+}
+
+// ObjectiveWeights turn the three objectives into a single score, lower is better.
+type ObjectiveWeights struct {
+	Risk    float64
+	Cost    float64
+	Latency float64
+}
+
+// paretoFront returns every Pareto-optimal placement ordered by increasing max risk.
+//
+// The latency of a placement is one of the data center latencies and its max risk is one of
+// risk^count, so every pair of such thresholds is tried: only data centers within the latency
+// may be used, and under the risk bound the cheapest data centers are filled first, which is
+// the cheapest placement for the pair. Dominated results are dropped.
+// That is |latencies| * |risk values| placements, meant for capacity planning, not hot paths.
+func paretoFront(dataCenters []PricedDataCenter, fragments int) ([]ParetoPoint, error) {
+	plain := make([]DataCenter, len(dataCenters))
+	for i, dc := range dataCenters {
+		if dc.Price < 0 || dc.Latency < 0 {
+			return nil, fmt.Errorf("%w: data center %s", ErrNegativeAttribute, dc.ID)
+		}
+		plain[i] = dc.DataCenter
+	}
+	if err := validateDataCenters(plain); err != nil {
+		return nil, err
+	}
+
+	var (
+		candidates []ParetoPoint
+		lastErr    error
+	)
+	for _, latency := range distinctLatencies(dataCenters) {
+		points, err := latencyCandidates(dataCenters, latency, fragments)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		candidates = append(candidates, points...)
+	}
+	if len(candidates) == 0 {
+		if lastErr == nil {
+			lastErr = ErrNoDataCenters
+		}
+		return nil, lastErr
+	}
+
+	return nonDominated(candidates), nil
+}
+
+// weightedOptimum is the placement with the lowest weighted sum of the objectives.
+// Positive weights always pick a Pareto-optimal placement, so it is searched on the front.
+func weightedOptimum(dataCenters []PricedDataCenter, fragments int, weights ObjectiveWeights) (ParetoPoint, error) {
+	if weights.Risk < 0 || weights.Cost < 0 || weights.Latency < 0 {
+		return ParetoPoint{}, fmt.Errorf("%w: weights %+v", ErrNegativeAttribute, weights)
+	}
+	front, err := paretoFront(dataCenters, fragments)
+	if err != nil {
+		return ParetoPoint{}, err
+	}
+
+	best, bestScore := 0, math.Inf(1)
+	for i, point := range front {
+		score := weights.Risk*float64(point.MaxRisk) + weights.Cost*point.Cost + weights.Latency*point.Latency
+		if score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return front[best], nil
+}
+
+func distinctLatencies(dataCenters []PricedDataCenter) []float64 {
+	latencies := make([]float64, 0, len(dataCenters))
+	for _, dc := range dataCenters {
+		latencies = append(latencies, dc.Latency)
+	}
+	sort.Float64s(latencies)
+
+	distinct := latencies[:0]
+	for i, latency := range latencies {
+		if i == 0 || latency != latencies[i-1] {
+			distinct = append(distinct, latency)
+		}
+	}
+	return distinct
+}
+
+// latencyCandidates tries every risk bound from the minimal one for data centers within
+// maxLatency up to the one where no capacity grows anymore.
+func latencyCandidates(dataCenters []PricedDataCenter, maxLatency float64, fragments int) ([]ParetoPoint, error) {
+	var usable []DataCenter
+	for _, dc := range dataCenters {
+		if dc.Latency <= maxLatency {
+			usable = append(usable, dc.DataCenter)
+		} else if dc.MinFragments > 0 {
+			return nil, fmt.Errorf("%w: data center %s is too slow for its minimum", ErrInsufficientCapacity, dc.ID)
+		}
+	}
+	minRisk, err := minimalBoundedRisk(usable, fragments, ExponentialRisk{})
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := []int64{minRisk}
+	for _, dc := range usable {
+		limit := fragments
+		if dc.MaxFragments != Unlimited {
+			limit = min(limit, dc.MaxFragments)
+		}
+		previous := int64(0)
+		for count := 1; count <= limit; count++ {
+			risk, ok := ExponentialRisk{}.Cost(dc.Risk, count)
+			if !ok || risk == previous { // risk 1 never grows, the rest of the loop adds nothing
+				break
+			}
+			previous = risk
+			if risk > minRisk {
+				bounds = append(bounds, risk)
+			}
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	points := make([]ParetoPoint, 0, len(bounds))
+	for i, maxRisk := range bounds {
+		if i == 0 || maxRisk != bounds[i-1] {
+			points = append(points, cheapestPlacement(dataCenters, maxLatency, fragments, maxRisk))
+		}
+	}
+	return points, nil
+}
+
+// cheapestPlacement places minimums first, the rest goes into the cheapest data centers
+// within maxLatency under maxRisk. Equal prices prefer lower risk, then input order.
+func cheapestPlacement(dataCenters []PricedDataCenter, maxLatency float64, fragments int, maxRisk int64) ParetoPoint {
+	order := make([]int, len(dataCenters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := dataCenters[order[i]], dataCenters[order[j]]
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.Risk < b.Risk
+	})
+
+	var (
+		plain              = make([]DataCenter, len(dataCenters))
+		placement          = Placement{Loads: make([]DataCenterLoad, len(dataCenters))}
+		remainingFragments = fragments
+	)
+	for i, dc := range dataCenters {
+		plain[i] = dc.DataCenter
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: dc.MinFragments}
+		remainingFragments -= dc.MinFragments
+	}
+	for _, i := range order {
+		if remainingFragments <= 0 {
+			break
+		}
+		if dataCenters[i].Latency > maxLatency {
+			continue
+		}
+		load := &placement.Loads[i]
+		extra := plain[i].capacity(maxRisk, load.Fragments+remainingFragments, ExponentialRisk{}) - load.Fragments
+		if extra > 0 {
+			load.Fragments += extra
+			remainingFragments -= extra
+		}
+	}
+	priceLoads(&placement, ExponentialRisk{})
+
+	point := ParetoPoint{MaxRisk: placement.MaxRisk, Placement: namePlacement(plain, placement)}
+	for i, load := range placement.Loads {
+		if load.Fragments > 0 {
+			point.Cost += float64(load.Fragments) * dataCenters[i].Price
+			point.Latency = max(point.Latency, dataCenters[i].Latency)
+		}
+	}
+	return point
+}
+
+// nonDominated keeps one point per Pareto-optimal objective triple, by increasing risk.
+func nonDominated(points []ParetoPoint) []ParetoPoint {
+	sort.SliceStable(points, func(i, j int) bool {
+		a, b := points[i], points[j]
+		if a.MaxRisk != b.MaxRisk {
+			return a.MaxRisk < b.MaxRisk
+		}
+		if a.Cost != b.Cost {
+			return a.Cost < b.Cost
+		}
+		return a.Latency < b.Latency
+	})
+
+	var front []ParetoPoint
+	for _, point := range points {
+		dominated := false
+		for _, kept := range front { // kept never has a higher risk
+			if kept.Cost <= point.Cost && kept.Latency <= point.Latency {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, point)
+		}
+	}
+	return front
+}
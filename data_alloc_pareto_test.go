@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParetoFront(t *testing.T) {
+	dataCenters := []PricedDataCenter{
+		{DataCenter: DataCenter{ID: "cheap", Risk: 10, MaxFragments: Unlimited}, Price: 1, Latency: 50},
+		{DataCenter: DataCenter{ID: "safe", Risk: 2, MaxFragments: Unlimited}, Price: 5, Latency: 10},
+	}
+	front, err := paretoFront(dataCenters, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		maxRisk int64
+		cost    float64
+		latency float64
+		cheap   int
+	}{
+		{8, 15, 10, 0},
+		{10, 11, 50, 1},
+		{100, 7, 50, 2},
+		{1000, 3, 50, 3},
+	}
+	if len(front) != len(expected) {
+		t.Fatalf("Expected %d points, but got %+v", len(expected), front)
+	}
+	for i, e := range expected {
+		point := front[i]
+		if point.MaxRisk != e.maxRisk || point.Cost != e.cost || point.Latency != e.latency {
+			t.Errorf("Expected (%d, %.1f, %.1f), but got (%d, %.1f, %.1f)",
+				e.maxRisk, e.cost, e.latency, point.MaxRisk, point.Cost, point.Latency)
+		}
+		if point.Placement.Loads["cheap"].Fragments != e.cheap {
+			t.Errorf("Expected %d fragments in cheap, but got %d", e.cheap, point.Placement.Loads["cheap"].Fragments)
+		}
+	}
+}
+
+func TestParetoFront_NoDominatedPoints(t *testing.T) {
+	dataCenters := []PricedDataCenter{
+		{DataCenter: DataCenter{ID: "a", Risk: 3, MaxFragments: Unlimited}, Price: 2, Latency: 20},
+		{DataCenter: DataCenter{ID: "b", Risk: 5, MaxFragments: 2}, Price: 1, Latency: 30},
+		{DataCenter: DataCenter{ID: "c", Risk: 2, MaxFragments: Unlimited}, Price: 4, Latency: 20},
+		{DataCenter: DataCenter{ID: "d", Risk: 7, MinFragments: 1, MaxFragments: Unlimited}, Price: 0.5, Latency: 40},
+	}
+	front, err := paretoFront(dataCenters, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, p := range front {
+		if p.Placement.Loads["d"].Fragments < 1 {
+			t.Errorf("minimum of d is broken: %+v", p.Placement.Loads)
+		}
+		for j, q := range front {
+			if i != j && q.MaxRisk <= p.MaxRisk && q.Cost <= p.Cost && q.Latency <= p.Latency {
+				t.Errorf("%+v is dominated by %+v", p, q)
+			}
+		}
+	}
+}
+
+func TestWeightedOptimum(t *testing.T) {
+	dataCenters := []PricedDataCenter{
+		{DataCenter: DataCenter{ID: "cheap", Risk: 10, MaxFragments: Unlimited}, Price: 1, Latency: 50},
+		{DataCenter: DataCenter{ID: "safe", Risk: 2, MaxFragments: Unlimited}, Price: 5, Latency: 10},
+	}
+	tests := []struct {
+		name    string
+		weights ObjectiveWeights
+		maxRisk int64
+	}{
+		{"CostOnly", ObjectiveWeights{Cost: 1}, 1000},
+		{"RiskAndCost", ObjectiveWeights{Risk: 1, Cost: 1}, 10},
+		{"LatencyOnly", ObjectiveWeights{Latency: 1}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point, err := weightedOptimum(dataCenters, 3, tt.weights)
+			if err != nil || point.MaxRisk != tt.maxRisk {
+				t.Errorf("Expected %d, but got %d, %v", tt.maxRisk, point.MaxRisk, err)
+			}
+		})
+	}
+
+	_, err := weightedOptimum(dataCenters, 3, ObjectiveWeights{Risk: -1})
+	if !errors.Is(err, ErrNegativeAttribute) {
+		t.Errorf("Expected %v, but got %v", ErrNegativeAttribute, err)
+	}
+}
+
+func TestParetoFront_RiskOne(t *testing.T) {
+	// every cost is 1, a single bound is all there is to try however many fragments
+	dataCenters := []PricedDataCenter{
+		{DataCenter: DataCenter{ID: "a", Risk: 1, MaxFragments: Unlimited}, Price: 1, Latency: 10},
+		{DataCenter: DataCenter{ID: "b", Risk: 1, MaxFragments: Unlimited}, Price: 2, Latency: 20},
+	}
+	front, err := paretoFront(dataCenters, 2000000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(front) != 1 || front[0].MaxRisk != 1 || front[0].Placement.Loads["a"].Fragments != 2000000 {
+		t.Errorf("Expected everything in a at risk 1, but got %+v", front)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const ErrNegativeSlack allocationError = "Error: rebalancing slack must not be negative."
+
+// Move is a batch of fragments to move from one data center to another.
+type Move struct {
+	From      string
+	To        string
+	Fragments int
+}
+
+// rebalanceFragments adapts the current placement (fragments by data center ID) to updated
+// data centers. The new placement stays within slack above the new optimal bound,
+// 0 means the optimum itself and 0.1 allows 10% more, while moving as few fragments as possible.
+// Fragments in data centers missing from dataCenters are always moved out.
+//
+// Every data center keeps its fragments clamped into [MinFragments, capacity under the bound].
+// That moves max(fragments above capacities, fragments below minimums) fragments,
+// and no placement within the bound can move less.
+func rebalanceFragments(current map[string]int, dataCenters []DataCenter, slack float64) (NamedPlacement, []Move, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return NamedPlacement{}, nil, err
+	}
+	if slack < 0 || math.IsNaN(slack) {
+		return NamedPlacement{}, nil, fmt.Errorf("%w: %v", ErrNegativeSlack, slack)
+	}
+
+	fragments := 0
+	for id, count := range current {
+		if count < 0 {
+			return NamedPlacement{}, nil, fmt.Errorf("%w: data center %s holds %d", ErrInvalidCapacity, id, count)
+		}
+		fragments += count
+	}
+
+	optimal, err := minimalBoundedRisk(dataCenters, fragments, ExponentialRisk{})
+	if err != nil {
+		return NamedPlacement{}, nil, err
+	}
+	maxRisk := int64(math.MaxInt64)
+	if extra := float64(optimal) * slack; extra < float64(math.MaxInt64-optimal) {
+		maxRisk = optimal + int64(extra) // optimal itself is not rounded through float64
+	}
+
+	placement := Placement{Loads: make([]DataCenterLoad, len(dataCenters))}
+	capacities := make([]int, len(dataCenters))
+	placed := 0
+	for i, dc := range dataCenters {
+		capacities[i] = dc.capacity(maxRisk, fragments, ExponentialRisk{})
+		count := max(min(current[dc.ID], capacities[i]), dc.MinFragments)
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk, Fragments: count}
+		placed += count
+	}
+
+	order := riskOrder(risksOf(dataCenters))
+	for _, i := range order { // fragments moved out of full data centers go to the least risky with room
+		if placed >= fragments {
+			break
+		}
+		load := &placement.Loads[i]
+		extra := min(capacities[i]-load.Fragments, fragments-placed)
+		load.Fragments += extra
+		placed += extra
+	}
+	for n := len(order) - 1; n >= 0 && placed > fragments; n-- { // minimums took more than was moved out
+		i := order[n]
+		load := &placement.Loads[i]
+		spare := min(load.Fragments-dataCenters[i].MinFragments, placed-fragments)
+		if spare > 0 {
+			load.Fragments -= spare
+			placed -= spare
+		}
+	}
+
+	priceLoads(&placement, ExponentialRisk{})
+	return namePlacement(dataCenters, placement), rebalanceMoves(current, dataCenters, placement), nil
+}
+
+// rebalanceMoves pairs surpluses with deficits. Sources are the unknown data centers sorted
+// by ID and then dataCenters in input order, destinations are in input order.
+func rebalanceMoves(current map[string]int, dataCenters []DataCenter, placement Placement) []Move {
+	type delta struct {
+		id        string
+		fragments int
+	}
+
+	var (
+		known        = make(map[string]struct{}, len(dataCenters))
+		sources      []delta
+		destinations []delta
+	)
+	for i, dc := range dataCenters {
+		known[dc.ID] = struct{}{}
+		if diff := placement.Loads[i].Fragments - current[dc.ID]; diff > 0 {
+			destinations = append(destinations, delta{dc.ID, diff})
+		}
+	}
+	for id, count := range current {
+		if _, ok := known[id]; !ok && count > 0 {
+			sources = append(sources, delta{id, count})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].id < sources[j].id })
+	for i, dc := range dataCenters {
+		if diff := current[dc.ID] - placement.Loads[i].Fragments; diff > 0 {
+			sources = append(sources, delta{dc.ID, diff})
+		}
+	}
+
+	var moves []Move
+	for len(sources) > 0 && len(destinations) > 0 {
+		from, to := &sources[0], &destinations[0]
+		count := min(from.fragments, to.fragments)
+		moves = append(moves, Move{From: from.id, To: to.id, Fragments: count})
+
+		if from.fragments -= count; from.fragments == 0 {
+			sources = sources[1:]
+		}
+		if to.fragments -= count; to.fragments == 0 {
+			destinations = destinations[1:]
+		}
+	}
+	return moves
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRebalanceFragments(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     map[string]int
+		dataCenters []DataCenter
+		slack       float64
+		maxRisk     int64
+		expected    map[string]int
+		moves       []Move
+	}{
+		{
+			name:        "RiskGrew",
+			current:     map[string]int{"fra": 2, "ams": 1, "lon": 2},
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 40, MaxFragments: Unlimited}},
+			maxRisk:     900,
+			expected:    map[string]int{"fra": 2, "ams": 2, "lon": 1},
+			moves:       []Move{{From: "lon", To: "ams", Fragments: 1}},
+		},
+		{
+			name:        "SlackAvoidsMoves",
+			current:     map[string]int{"fra": 2, "ams": 1, "lon": 2},
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 40, MaxFragments: Unlimited}},
+			slack:       1,
+			maxRisk:     1600,
+			expected:    map[string]int{"fra": 2, "ams": 1, "lon": 2},
+		},
+		{
+			name:        "RiskDropped",
+			current:     map[string]int{"fra": 2, "ams": 1, "lon": 2},
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 2, MaxFragments: Unlimited}},
+			maxRisk:     16,
+			expected:    map[string]int{"fra": 1, "ams": 0, "lon": 4},
+			moves:       []Move{{From: "fra", To: "lon", Fragments: 1}, {From: "ams", To: "lon", Fragments: 1}},
+		},
+		{
+			name:        "DecommissionedDataCenter",
+			current:     map[string]int{"old": 3},
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 20, MaxFragments: Unlimited}},
+			maxRisk:     100,
+			expected:    map[string]int{"fra": 2, "ams": 1},
+			moves:       []Move{{From: "old", To: "fra", Fragments: 2}, {From: "old", To: "ams", Fragments: 1}},
+		},
+		{
+			name:        "MinimumPullsFragments",
+			current:     map[string]int{"fra": 3, "ams": 0},
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 10, MinFragments: 1, MaxFragments: Unlimited}},
+			slack:       10,
+			maxRisk:     100,
+			expected:    map[string]int{"fra": 2, "ams": 1},
+			moves:       []Move{{From: "fra", To: "ams", Fragments: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, moves, err := rebalanceFragments(tt.current, tt.dataCenters, tt.slack)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk {
+				t.Errorf("Expected %d, but got %d", tt.maxRisk, placement.MaxRisk)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+			if len(moves) != len(tt.moves) {
+				t.Fatalf("Expected moves %v, but got %v", tt.moves, moves)
+			}
+			for i := range moves {
+				if moves[i] != tt.moves[i] {
+					t.Errorf("Expected moves %v, but got %v", tt.moves, moves)
+				}
+			}
+		})
+	}
+}
+
+func TestRebalanceFragments_Errors(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: 1}}
+	if _, _, err := rebalanceFragments(map[string]int{"fra": 1}, dataCenters, -0.5); !errors.Is(err, ErrNegativeSlack) {
+		t.Errorf("Expected %v, but got %v", ErrNegativeSlack, err)
+	}
+	if _, _, err := rebalanceFragments(map[string]int{"fra": 2}, dataCenters, 0); !errors.Is(err, ErrInsufficientCapacity) {
+		t.Errorf("Expected %v, but got %v", ErrInsufficientCapacity, err)
+	}
+	if _, _, err := rebalanceFragments(map[string]int{"fra": -1}, dataCenters, 0); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected %v, but got %v", ErrInvalidCapacity, err)
+	}
+}
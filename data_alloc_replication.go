@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+const ErrInvalidReplicationFactor allocationError = "Error: replication factor must be between 1 and the number of data centers."
+
+// ReplicaPlacement is a placement where every fragment is stored in several distinct data centers.
+type ReplicaPlacement struct {
+	NamedPlacement            // counts replicas, not fragments
+	Replicas       [][]string // data center IDs holding each fragment, least risky first
+}
+
+// distributeReplicas places every fragment into replicas distinct data centers, minimizing
+// the max risk^count where count is the number of replicas a data center holds.
+// Distinctness only limits a data center to one replica per fragment, so the search is the
+// usual one over fragments*replicas replicas with quotas capped at fragments.
+func distributeReplicas(dataCenters []DataCenter, fragments, replicas int) (ReplicaPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return ReplicaPlacement{}, err
+	}
+	if replicas < 1 || replicas > len(dataCenters) {
+		return ReplicaPlacement{}, fmt.Errorf("%w: %d replicas, %d data centers", ErrInvalidReplicationFactor, replicas, len(dataCenters))
+	}
+	if fragments <= 0 {
+		fragments = 0
+	}
+
+	capped := make([]DataCenter, len(dataCenters))
+	for i, dc := range dataCenters {
+		if dc.MinFragments > fragments {
+			return ReplicaPlacement{}, fmt.Errorf("%w: data center %s needs %d replicas of %d fragments", ErrMinimumExceedsFragments, dc.ID, dc.MinFragments, fragments)
+		}
+		if dc.MaxFragments == Unlimited || dc.MaxFragments > fragments {
+			dc.MaxFragments = fragments
+		}
+		capped[i] = dc
+	}
+
+	total := fragments * replicas
+	maxRisk, err := minimalBoundedRisk(capped, total, ExponentialRisk{})
+	if err != nil {
+		return ReplicaPlacement{}, err
+	}
+	placement := placeFragments(capped, total, maxRisk, ExponentialRisk{})
+
+	return ReplicaPlacement{
+		NamedPlacement: namePlacement(dataCenters, placement),
+		Replicas:       assignReplicas(dataCenters, placement, fragments),
+	}, nil
+}
+
+// assignReplicas deals replicas out round-robin: every data center takes consecutive
+// slots, slot p goes to fragment p % fragments. A data center holds at most fragments
+// replicas, so its slots never wrap onto the same fragment twice.
+func assignReplicas(dataCenters []DataCenter, placement Placement, fragments int) [][]string {
+	replicas := make([][]string, fragments)
+	slot := 0
+	for _, i := range riskOrder(risksOf(dataCenters)) {
+		for n := 0; n < placement.Loads[i].Fragments; n++ {
+			fragment := slot % fragments
+			replicas[fragment] = append(replicas[fragment], dataCenters[i].ID)
+			slot++
+		}
+	}
+	return replicas
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistributeReplicas(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 20, MaxFragments: Unlimited}, {ID: "lon", Risk: 30, MaxFragments: Unlimited}}
+		placement, err := distributeReplicas(dataCenters, 2, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.MaxRisk != 100 {
+			t.Errorf("Expected 100, but got %d", placement.MaxRisk)
+		}
+		expected := [][]string{{"fra", "ams"}, {"fra", "lon"}}
+		for i := range expected {
+			for j := range expected[i] {
+				if placement.Replicas[i][j] != expected[i][j] {
+					t.Fatalf("Expected %v, but got %v", expected, placement.Replicas)
+				}
+			}
+		}
+	})
+
+	t.Run("DistinctDataCenters", func(t *testing.T) {
+		dataCenters := []DataCenter{
+			{ID: "a", Risk: 2, MaxFragments: Unlimited}, {ID: "b", Risk: 3, MaxFragments: Unlimited}, {ID: "c", Risk: 5, MaxFragments: Unlimited}, {ID: "d", Risk: 7, MaxFragments: 1},
+		}
+		placement, err := distributeReplicas(dataCenters, 10, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts := make(map[string]int)
+		for i, replicas := range placement.Replicas {
+			if len(replicas) != 3 {
+				t.Errorf("fragment %d: Expected 3 replicas, but got %v", i, replicas)
+			}
+			seen := make(map[string]bool)
+			for _, id := range replicas {
+				if seen[id] {
+					t.Errorf("fragment %d: %s holds two replicas", i, id)
+				}
+				seen[id] = true
+				counts[id]++
+			}
+		}
+		for id, load := range placement.Loads {
+			if counts[id] != load.Fragments {
+				t.Errorf("%s: Expected %d replicas, but got %d", id, load.Fragments, counts[id])
+			}
+			if load.Fragments > 10 {
+				t.Errorf("%s holds more replicas than fragments: %d", id, load.Fragments)
+			}
+		}
+	})
+
+	t.Run("SingleReplicaMatchesNamed", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 5, MaxFragments: Unlimited}, {ID: "ams", Risk: 10, MaxFragments: Unlimited}, {ID: "lon", Risk: 7, MaxFragments: Unlimited}}
+		named, _ := distributeNamedFragments(dataCenters, 10)
+		placement, err := distributeReplicas(dataCenters, 10, 1)
+		if err != nil || placement.MaxRisk != named.MaxRisk {
+			t.Errorf("Expected %d, but got %d, %v", named.MaxRisk, placement.MaxRisk, err)
+		}
+	})
+
+	t.Run("InvalidReplicationFactor", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 20, MaxFragments: Unlimited}}
+		for _, replicas := range []int{0, 3} {
+			_, err := distributeReplicas(dataCenters, 2, replicas)
+			if !errors.Is(err, ErrInvalidReplicationFactor) {
+				t.Errorf("%d: Expected %v, but got %v", replicas, ErrInvalidReplicationFactor, err)
+			}
+		}
+	})
+
+	t.Run("MinimumAboveFragments", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 10, MinFragments: 3, MaxFragments: Unlimited}, {ID: "ams", Risk: 20, MaxFragments: Unlimited}}
+		_, err := distributeReplicas(dataCenters, 2, 2)
+		if !errors.Is(err, ErrMinimumExceedsFragments) {
+			t.Errorf("Expected %v, but got %v", ErrMinimumExceedsFragments, err)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+const ErrInvalidSpread allocationError = "Error: spread must be between 0 and the number of data centers."
+
+// distributeSpreadFragments is distributeNamedFragments where fragments must end up in at
+// least spread distinct data centers. The bound grows until enough data centers take a
+// fragment under it, so the max risk is minimal among spread placements.
+func distributeSpreadFragments(dataCenters []DataCenter, fragments, spread int) (NamedPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return NamedPlacement{}, err
+	}
+	if spread < 0 || spread > len(dataCenters) {
+		return NamedPlacement{}, fmt.Errorf("%w: spread %d, %d data centers", ErrInvalidSpread, spread, len(dataCenters))
+	}
+
+	// data centers with minimums count towards the spread, the others need a fragment each
+	required, used := 0, 0
+	for _, dc := range dataCenters {
+		required += dc.MinFragments
+		if dc.MinFragments > 0 {
+			used++
+		}
+	}
+	if required += max(spread-used, 0); required > max(fragments, 0) {
+		return NamedPlacement{}, fmt.Errorf("%w: %d required for spread %d, %d to place", ErrMinimumExceedsFragments, required, spread, fragments)
+	}
+
+	model := ExponentialRisk{}
+	maxRisk, err := searchBoundedRisk(dataCenters, fragments, model, func(maxRisk int64) int {
+		if spreadUnder(dataCenters, maxRisk, model) < spread {
+			return 0
+		}
+		return boundedCapacity(dataCenters, maxRisk, fragments, model)
+	})
+	if err != nil {
+		return NamedPlacement{}, err
+	}
+	return namePlacement(dataCenters, placeSpreadFragments(dataCenters, fragments, spread, maxRisk, model)), nil
+}
+
+// spreadUnder counts data centers that take at least one fragment under maxRisk.
+func spreadUnder(dataCenters []DataCenter, maxRisk int64, model RiskModel) int {
+	count := 0
+	for _, dc := range dataCenters {
+		count += dc.capacity(maxRisk, 1, model)
+	}
+	return count
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistributeSpreadFragments(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}, {ID: "lon", Risk: 1000, MaxFragments: Unlimited}}
+
+	cases := []struct {
+		spread   int
+		expected int64
+		loads    map[string]int
+	}{
+		{0, 8, map[string]int{"fra": 3, "ams": 1, "lon": 0}},
+		{2, 8, map[string]int{"fra": 3, "ams": 1, "lon": 0}},
+		{3, 1000, map[string]int{"fra": 2, "ams": 1, "lon": 1}},
+	}
+	for _, c := range cases {
+		placement, err := distributeSpreadFragments(dataCenters, 4, c.spread)
+		if err != nil {
+			t.Fatalf("spread %d: unexpected error: %v", c.spread, err)
+		}
+		if placement.MaxRisk != c.expected {
+			t.Errorf("spread %d: Expected %d, but got %d", c.spread, c.expected, placement.MaxRisk)
+		}
+		for id, fragments := range c.loads {
+			if placement.Loads[id].Fragments != fragments {
+				t.Errorf("spread %d: Expected %d fragments in %s, but got %d", c.spread, fragments, id, placement.Loads[id].Fragments)
+			}
+		}
+	}
+
+	t.Run("MinimumsCountTowardsSpread", func(t *testing.T) {
+		pinned := []DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}, {ID: "lon", Risk: 1000, MinFragments: 1, MaxFragments: Unlimited}}
+		placement, err := distributeSpreadFragments(pinned, 4, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.Loads["lon"].Fragments != 1 || placement.Loads["fra"].Fragments != 3 || placement.Bottleneck != "lon" {
+			t.Errorf("Expected 3 in fra and 1 in lon, but got %+v", placement.Loads)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			dataCenters []DataCenter
+			fragments   int
+			spread      int
+			expected    error
+		}{
+			{dataCenters, 4, 4, ErrInvalidSpread},
+			{dataCenters, 4, -1, ErrInvalidSpread},
+			{dataCenters, 2, 3, ErrMinimumExceedsFragments},
+			{[]DataCenter{{ID: "fra", Risk: 2, MinFragments: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}}, 2, 2, ErrMinimumExceedsFragments},
+			{[]DataCenter{{ID: "fra", Risk: 2, MaxFragments: 1}, {ID: "ams", Risk: 3, MaxFragments: 1}}, 3, 2, ErrInsufficientCapacity},
+		}
+		for _, c := range cases {
+			if _, err := distributeSpreadFragments(c.dataCenters, c.fragments, c.spread); !errors.Is(err, c.expected) {
+				t.Errorf("%d fragments, spread %d: Expected %v, but got %v", c.fragments, c.spread, c.expected, err)
+			}
+		}
+	})
+}
+
+func TestDistributeSpreadFragments_BruteForce(t *testing.T) {
+	inputs := [][]DataCenter{
+		{{ID: "a", Risk: 2, MaxFragments: Unlimited}, {ID: "b", Risk: 7, MaxFragments: Unlimited}, {ID: "c", Risk: 50, MaxFragments: Unlimited}, {ID: "d", Risk: 3, MaxFragments: Unlimited}},
+		{{ID: "a", Risk: 5, MaxFragments: 2}, {ID: "b", Risk: 5, MaxFragments: Unlimited}, {ID: "c", Risk: 9, MinFragments: 1, MaxFragments: Unlimited}},
+		{{ID: "a", Risk: 1, MaxFragments: Unlimited}, {ID: "b", Risk: 100, MaxFragments: Unlimited}, {ID: "c", Risk: 100, MaxFragments: 1}},
+	}
+	for _, dataCenters := range inputs {
+		for fragments := 0; fragments <= 6; fragments++ {
+			for spread := 0; spread <= len(dataCenters); spread++ {
+				expected, ok := bruteForceSpread(dataCenters, fragments, spread)
+				placement, err := distributeSpreadFragments(dataCenters, fragments, spread)
+				if ok != (err == nil) || (ok && placement.MaxRisk != expected) {
+					t.Errorf("%+v, %d fragments, spread %d: Expected %d (%v), but got %d, %v",
+						dataCenters, fragments, spread, expected, ok, placement.MaxRisk, err)
+					continue
+				}
+				if !ok {
+					continue
+				}
+
+				total, used := 0, 0
+				for _, dc := range dataCenters {
+					load := placement.Loads[dc.ID]
+					total += load.Fragments
+					if load.Fragments > 0 {
+						used++
+					}
+				}
+				if total != fragments || used < spread {
+					t.Errorf("%+v, %d fragments, spread %d: got %d fragments in %d data centers",
+						dataCenters, fragments, spread, total, used)
+				}
+			}
+		}
+	}
+}
+
+// bruteForceSpread tries every placement of fragments.
+func bruteForceSpread(dataCenters []DataCenter, fragments, spread int) (int64, bool) {
+	best, found := int64(0), false
+	loads := make([]int, len(dataCenters))
+
+	var walk func(i, remaining int)
+	walk = func(i, remaining int) {
+		if i == len(dataCenters) {
+			if remaining > 0 {
+				return
+			}
+			var maxRisk int64
+			used := 0
+			for j, count := range loads {
+				if count > 0 {
+					used++
+					cost, _ := checkedPow(dataCenters[j].Risk, count)
+					maxRisk = max(maxRisk, cost)
+				}
+			}
+			if used >= spread && (!found || maxRisk < best) {
+				best, found = maxRisk, true
+			}
+			return
+		}
+		dc := dataCenters[i]
+		for count := dc.MinFragments; count <= remaining && (dc.MaxFragments == Unlimited || count <= dc.MaxFragments); count++ {
+			loads[i] = count
+			walk(i+1, remaining-count)
+		}
+	}
+	walk(0, fragments)
+	return best, found
+}
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"testing"
+)
 
 func TestDistributeFragments(t *testing.T) {
 	t.Run("Simple", TestDistributeFragments_Simple)
@@ -105,3 +109,309 @@ func TestDistributeFragments_LargeInput(t *testing.T) {
 	fragments := 50
 	_ = distributeFragments(dataCenters, fragments)
 }
+
+func TestDistributeFragmentsPlan(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		dataCenters := []int{10, 30, 20}
+		maxRisk, placement := distributeFragmentsPlan(dataCenters, 5)
+		if maxRisk != 400 || placement.MaxRisk != 400 {
+			t.Fatalf("Expected 400, but got %d and %d", maxRisk, placement.MaxRisk)
+		}
+		expected := []DataCenterLoad{
+			{Index: 0, Risk: 10, Fragments: 2, Cost: 100},
+			{Index: 1, Risk: 30, Fragments: 1, Cost: 30},
+			{Index: 2, Risk: 20, Fragments: 2, Cost: 400},
+		}
+		for i, load := range placement.Loads {
+			if load != expected[i] {
+				t.Errorf("Expected %+v, but got %+v", expected[i], load)
+			}
+		}
+		if placement.Bottleneck != 2 {
+			t.Errorf("Expected bottleneck 2, but got %d", placement.Bottleneck)
+		}
+		if dataCenters[0] != 10 || dataCenters[1] != 30 || dataCenters[2] != 20 {
+			t.Errorf("input must not be reordered, got %v", dataCenters)
+		}
+	})
+
+	t.Run("EqualRisksTieBreak", func(t *testing.T) {
+		_, placement := distributeFragmentsPlan([]int{10, 10, 10}, 4)
+		expected := []int{2, 2, 0}
+		for i, load := range placement.Loads {
+			if load.Fragments != expected[i] {
+				t.Errorf("Expected %d fragments in %d, but got %d", expected[i], i, load.Fragments)
+			}
+		}
+		if placement.Bottleneck != 0 {
+			t.Errorf("Expected bottleneck 0, but got %d", placement.Bottleneck)
+		}
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		_, first := distributeFragmentsPlan([]int{5, 10, 7}, 10)
+		for i := 0; i < 10; i++ {
+			_, next := distributeFragmentsPlan([]int{5, 10, 7}, 10)
+			for j := range first.Loads {
+				if first.Loads[j] != next.Loads[j] {
+					t.Fatalf("Expected %+v, but got %+v", first.Loads[j], next.Loads[j])
+				}
+			}
+		}
+	})
+
+	t.Run("NoFragments", func(t *testing.T) {
+		maxRisk, placement := distributeFragmentsPlan([]int{10, 20}, 0)
+		if maxRisk != 0 || placement.Bottleneck != -1 || len(placement.Loads) != 2 {
+			t.Errorf("Expected empty placement, but got %d %+v", maxRisk, placement)
+		}
+	})
+}
+
+func TestDistributeFragmentsChecked(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		dataCenters := []int{10, 30, 20}
+		result, err := distributeFragmentsChecked(dataCenters, 5)
+		if err != nil || result != 400 {
+			t.Errorf("Expected 400, but got %d, %v", result, err)
+		}
+		if dataCenters[0] != 10 || dataCenters[1] != 30 || dataCenters[2] != 20 {
+			t.Errorf("input must not be reordered, got %v", dataCenters)
+		}
+	})
+
+	t.Run("SameAsUnchecked", func(t *testing.T) {
+		cases := []struct {
+			dataCenters []int
+			fragments   int
+		}{
+			{[]int{10, 10, 10}, 4},
+			{[]int{10}, 3},
+			{[]int{5, 10, 7}, 10},
+			{[]int{1000000}, 2},
+			{[]int{1, 50}, 7},
+		}
+		for _, c := range cases {
+			expected := distributeFragments(append([]int(nil), c.dataCenters...), c.fragments)
+			result, err := distributeFragmentsChecked(c.dataCenters, c.fragments)
+			if err != nil || result != expected {
+				t.Errorf("%v: Expected %d, but got %d, %v", c.dataCenters, expected, result, err)
+			}
+		}
+	})
+
+	t.Run("NoOverflowOnRealisticInput", func(t *testing.T) {
+		dataCenters := make([]int, 30)
+		for i := range dataCenters {
+			dataCenters[i] = 50
+		}
+		result, err := distributeFragmentsChecked(dataCenters, 40)
+		if err != nil || result != 2500 {
+			t.Errorf("Expected 2500, but got %d, %v", result, err)
+		}
+	})
+
+	t.Run("LargeInput", func(t *testing.T) {
+		result, err := distributeFragmentsChecked([]int{5, 10, 15, 20, 25, 30, 35, 40}, 50)
+		if err != nil || result != 100000000 { // 10^8
+			t.Errorf("Expected 100000000, but got %d, %v", result, err)
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		_, err := distributeFragmentsChecked([]int{1000, 2000}, 20)
+		if !errors.Is(err, ErrRiskOverflow) {
+			t.Errorf("Expected %v, but got %v", ErrRiskOverflow, err)
+		}
+	})
+
+	t.Run("EmptyCenters", func(t *testing.T) {
+		_, err := distributeFragmentsChecked(nil, 5)
+		if !errors.Is(err, ErrNoDataCenters) {
+			t.Errorf("Expected %v, but got %v", ErrNoDataCenters, err)
+		}
+	})
+
+	t.Run("NonPositiveRisk", func(t *testing.T) {
+		_, err := distributeFragmentsChecked([]int{10, 0, 20}, 5)
+		if !errors.Is(err, ErrNonPositiveRisk) {
+			t.Errorf("Expected %v, but got %v", ErrNonPositiveRisk, err)
+		}
+	})
+
+	t.Run("NoFragments", func(t *testing.T) {
+		result, err := distributeFragmentsChecked([]int{10, 20}, 0)
+		if err != nil || result != 0 {
+			t.Errorf("Expected 0, but got %d, %v", result, err)
+		}
+	})
+}
+
+func TestFragmentsUnderRisk(t *testing.T) {
+	const risk39 = 4052555153018976267 // 3^39, above float64 precision
+	tests := []struct {
+		name     string
+		risk     int
+		maxRisk  int64
+		limit    int
+		expected int
+	}{
+		{"Exact", 10, 1000, 100, 3},
+		{"BelowPower", 10, 999, 100, 2},
+		{"AbovePower", 10, 1001, 100, 3},
+		{"Limit", 2, 1024, 4, 4},
+		{"RiskOne", 1, 1, 7, 7},
+		{"TooLow", 5, 4, 10, 0},
+		{"FloatBoundary", 3, risk39, 100, 39},
+		{"FloatBoundaryMinusOne", 3, risk39 - 1, 100, 38},
+		{"MaxInt64", 2, math.MaxInt64, 100, 62},
+		{"HugeRisk", math.MaxInt32, math.MaxInt64, 100, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fragmentsUnderRisk(tt.risk, tt.maxRisk, tt.limit)
+			if result != tt.expected {
+				t.Errorf("Expected %d, but got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+// legacyIsRiskAchievable is the float64 math.Pow loop isRiskAchievable used before, kept for benchmarks.
+func legacyIsRiskAchievable(maxRisk int64, dataCenters []int, fragments int) bool {
+	remainingFragments := fragments
+	for _, risk := range dataCenters {
+		count := 0
+		for count < remainingFragments && math.Pow(float64(risk), float64(count+1)) <= float64(maxRisk) {
+			count++
+		}
+		remainingFragments -= count
+		if remainingFragments <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// 10k data centers can't hold 1M fragments, so every check walks the whole inventory.
+func benchmarkInventory() ([]int, int) {
+	dataCenters := make([]int, 10000)
+	for i := range dataCenters {
+		dataCenters[i] = 2 + i/10
+	}
+	return dataCenters, 1000000
+}
+
+func BenchmarkIsRiskAchievable(b *testing.B) {
+	dataCenters, fragments := benchmarkInventory()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		isRiskAchievable(math.MaxInt64/3, dataCenters, fragments)
+	}
+}
+
+func BenchmarkIsRiskAchievable_LegacyFloat(b *testing.B) {
+	dataCenters, fragments := benchmarkInventory()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		legacyIsRiskAchievable(math.MaxInt64/3, dataCenters, fragments)
+	}
+}
+
+func TestDistributeErasureCoded(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataCenters []DataCenter
+		required    int
+		total       int
+		failures    int
+		maxRisk     int64
+		expected    map[string]int
+	}{
+		{
+			name:        "NoFailuresMatchesNamed",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}},
+			required:    3,
+			total:       5,
+			maxRisk:     400,
+			expected:    map[string]int{"fra": 2, "ams": 1, "lon": 2},
+		},
+		{
+			name:        "SingleFailure",
+			dataCenters: []DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}, {ID: "lon", Risk: 5, MaxFragments: Unlimited}},
+			required:    4,
+			total:       6,
+			failures:    1,
+			maxRisk:     25, // 9 without survivability: fra 3, ams 2, lon 1
+			expected:    map[string]int{"fra": 2, "ams": 2, "lon": 2},
+		},
+		{
+			name: "TwoFailures",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 2, MaxFragments: Unlimited}, {ID: "lon", Risk: 2, MaxFragments: Unlimited}, {ID: "par", Risk: 2, MaxFragments: Unlimited},
+			},
+			required: 2,
+			total:    4,
+			failures: 2,
+			maxRisk:  2,
+			expected: map[string]int{"fra": 1, "ams": 1, "lon": 1, "par": 1},
+		},
+		{
+			name: "UnevenLoadsSurvive",
+			dataCenters: []DataCenter{
+				{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}, {ID: "lon", Risk: 3, MaxFragments: Unlimited}, {ID: "par", Risk: 3, MaxFragments: Unlimited},
+			},
+			required: 3,
+			total:    8,
+			failures: 2,
+			maxRisk:  9, // fra 3 and ams 2 lost together still leave 3
+			expected: map[string]int{"fra": 3, "ams": 2, "lon": 2, "par": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := distributeErasureCoded(tt.dataCenters, tt.required, tt.total, tt.failures)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk {
+				t.Errorf("Expected %d, but got %d", tt.maxRisk, placement.MaxRisk)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeErasureCoded_Errors(t *testing.T) {
+	dataCenters := []DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: Unlimited}}
+	tests := []struct {
+		name        string
+		dataCenters []DataCenter
+		required    int
+		total       int
+		failures    int
+		expected    error
+	}{
+		{"RequiredAboveTotal", dataCenters, 5, 4, 1, ErrInvalidErasureCode},
+		{"NegativeFailures", dataCenters, 2, 4, -1, ErrInvalidErasureCode},
+		{"AllDataCentersFail", dataCenters, 2, 4, 2, ErrUnsurvivable},
+		{"QuotaTooSmall", []DataCenter{{ID: "fra", Risk: 2, MaxFragments: 1}, {ID: "ams", Risk: 3, MaxFragments: 1}}, 1, 3, 0, ErrInsufficientCapacity},
+		{"QuotaBreaksSurvival", []DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 3, MaxFragments: 1}}, 2, 4, 1, ErrUnsurvivable},
+		{"NoDataCenters", nil, 1, 3, 0, ErrNoDataCenters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := distributeErasureCoded(tt.dataCenters, tt.required, tt.total, tt.failures)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
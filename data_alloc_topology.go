@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// FailureDomain is a node of the failure topology, e.g. region -> data center -> rack.
+// Fragments are stored in the leaves, a domain holds everything stored below it.
+// Every domain is bounded by the same max risk: Risk^fragments under it, so one regional
+// outage can't take more fragments than the region's risk allows.
+type FailureDomain struct {
+	ID           string
+	Risk         int
+	MaxFragments int // Unlimited if there is no quota, e.g. "no more than X fragments per region"
+	Children     []FailureDomain
+}
+
+// DomainLoad is what landed in a single failure domain.
+type DomainLoad struct {
+	Parent    string // empty for top level domains
+	Risk      int
+	Fragments int
+	Cost      int64 // Risk^Fragments, empty domain costs nothing
+}
+
+// DomainPlacement is a placement over the whole topology.
+type DomainPlacement struct {
+	MaxRisk    int64
+	Loads      map[string]DomainLoad // every domain of every level by ID
+	Bottleneck string                // first domain (depth-first) holding MaxRisk
+}
+
+// distributeAcrossDomains finds the minimal max risk over all levels of the topology.
+// The capacity of a domain under a bound is the smaller of its own capacity and the sum
+// of its children, so the greedy top-down fill below is exact.
+func distributeAcrossDomains(domains []FailureDomain, fragments int) (DomainPlacement, error) {
+	if err := validateDomains(domains, make(map[string]struct{})); err != nil {
+		return DomainPlacement{}, err
+	}
+
+	placement := DomainPlacement{Loads: make(map[string]DomainLoad)}
+	if fragments <= 0 {
+		fillDomains(domains, "", 0, 0, placement.Loads)
+		return placement, nil
+	}
+	if len(domains) == 0 {
+		return DomainPlacement{}, ErrNoDataCenters
+	}
+
+	achievable := func(maxRisk int64) bool {
+		return domainsCapacity(domains, maxRisk, fragments) >= fragments
+	}
+	if !achievable(math.MaxInt64) {
+		if quota := domainsCapacity(domains, -1, fragments); quota < fragments {
+			return DomainPlacement{}, fmt.Errorf("%w: quota %d, %d to place", ErrInsufficientCapacity, quota, fragments)
+		}
+		return DomainPlacement{}, ErrRiskOverflow
+	}
+
+	maxRisk, err := searchMinimalRisk(0, math.MaxInt64, achievable)
+	if err != nil {
+		return DomainPlacement{}, err
+	}
+
+	fillDomains(domains, "", maxRisk, fragments, placement.Loads)
+	forEachDomain(domains, func(domain FailureDomain) {
+		if load := placement.Loads[domain.ID]; load.Cost > placement.MaxRisk {
+			placement.MaxRisk = load.Cost
+			placement.Bottleneck = domain.ID
+		}
+	})
+	return placement, nil
+}
+
+func validateDomains(domains []FailureDomain, seen map[string]struct{}) error {
+	for _, domain := range domains {
+		if _, ok := seen[domain.ID]; ok || domain.ID == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidDataCenterID, domain.ID)
+		}
+		seen[domain.ID] = struct{}{}
+
+		if domain.Risk <= 0 {
+			return fmt.Errorf("%w: domain %s has risk %d", ErrNonPositiveRisk, domain.ID, domain.Risk)
+		}
+		if domain.MaxFragments < Unlimited {
+			return fmt.Errorf("%w: domain %s has max %d", ErrInvalidCapacity, domain.ID, domain.MaxFragments)
+		}
+		if err := validateDomains(domain.Children, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// domainsCapacity sums capacities (up to limit) under maxRisk, negative maxRisk ignores risk
+// and counts quotas only.
+func domainsCapacity(domains []FailureDomain, maxRisk int64, limit int) int {
+	total := 0
+	for _, domain := range domains {
+		if total >= limit {
+			break
+		}
+		total += domain.capacity(maxRisk, limit-total)
+	}
+	return total
+}
+
+func (d FailureDomain) capacity(maxRisk int64, limit int) int {
+	if d.MaxFragments != Unlimited {
+		limit = min(limit, d.MaxFragments)
+	}
+	if maxRisk >= 0 {
+		limit = fragmentsUnderRisk(d.Risk, maxRisk, limit)
+	}
+	if len(d.Children) == 0 {
+		return limit
+	}
+	return domainsCapacity(d.Children, maxRisk, limit)
+}
+
+// fillDomains puts fragments into the least risky domains first, equal risks in input order.
+// fragments must not exceed domainsCapacity, every domain gets a load even if empty.
+func fillDomains(domains []FailureDomain, parent string, maxRisk int64, fragments int, loads map[string]DomainLoad) {
+	risks := make([]int, len(domains))
+	for i, domain := range domains {
+		risks[i] = domain.Risk
+	}
+
+	for _, i := range riskOrder(risks) {
+		domain := domains[i]
+		count := 0
+		if fragments > 0 {
+			count = domain.capacity(maxRisk, fragments)
+		}
+		cost, _ := ExponentialRisk{}.Cost(domain.Risk, count) // fits, it is under maxRisk
+		loads[domain.ID] = DomainLoad{Parent: parent, Risk: domain.Risk, Fragments: count, Cost: cost}
+
+		fillDomains(domain.Children, domain.ID, maxRisk, count, loads)
+		fragments -= count
+	}
+}
+
+func forEachDomain(domains []FailureDomain, fn func(FailureDomain)) {
+	for _, domain := range domains {
+		fn(domain)
+		forEachDomain(domain.Children, fn)
+	}
+}
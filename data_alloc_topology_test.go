@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistributeAcrossDomains(t *testing.T) {
+	tests := []struct {
+		name       string
+		domains    []FailureDomain
+		fragments  int
+		maxRisk    int64
+		bottleneck string
+		expected   map[string]int
+	}{
+		{
+			name: "FlatMatchesNamed",
+			domains: []FailureDomain{
+				{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited},
+			},
+			fragments:  5,
+			maxRisk:    400,
+			bottleneck: "lon",
+			expected:   map[string]int{"fra": 2, "ams": 1, "lon": 2},
+		},
+		{
+			name: "RegionQuota",
+			domains: []FailureDomain{
+				{ID: "eu", Risk: 1, MaxFragments: 2, Children: []FailureDomain{
+					{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 10, MaxFragments: Unlimited},
+				}},
+				{ID: "us", Risk: 1, MaxFragments: Unlimited, Children: []FailureDomain{
+					{ID: "nyc", Risk: 20, MaxFragments: Unlimited},
+				}},
+			},
+			fragments:  4,
+			maxRisk:    400,
+			bottleneck: "nyc",
+			expected:   map[string]int{"eu": 2, "fra": 2, "ams": 0, "us": 2, "nyc": 2},
+		},
+		{
+			name: "RegionRisk",
+			domains: []FailureDomain{
+				{ID: "eu", Risk: 3, MaxFragments: Unlimited, Children: []FailureDomain{
+					{ID: "fra", Risk: 2, MaxFragments: Unlimited}, {ID: "ams", Risk: 2, MaxFragments: Unlimited},
+				}},
+				{ID: "us", Risk: 5, MaxFragments: Unlimited, Children: []FailureDomain{
+					{ID: "nyc", Risk: 2, MaxFragments: Unlimited},
+				}},
+			},
+			fragments:  4,
+			maxRisk:    25,
+			bottleneck: "us",
+			expected:   map[string]int{"eu": 2, "fra": 2, "us": 2, "nyc": 2},
+		},
+		{
+			name: "Racks",
+			domains: []FailureDomain{
+				{ID: "eu", Risk: 1, MaxFragments: Unlimited, Children: []FailureDomain{
+					{ID: "fra", Risk: 3, MaxFragments: Unlimited, Children: []FailureDomain{
+						{ID: "fra-1", Risk: 2, MaxFragments: 1}, {ID: "fra-2", Risk: 7, MaxFragments: Unlimited},
+					}},
+				}},
+			},
+			fragments:  3,
+			maxRisk:    49,
+			bottleneck: "fra-2",
+			expected:   map[string]int{"eu": 3, "fra": 3, "fra-1": 1, "fra-2": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := distributeAcrossDomains(tt.domains, tt.fragments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if placement.MaxRisk != tt.maxRisk || placement.Bottleneck != tt.bottleneck {
+				t.Errorf("Expected %d in %s, but got %d in %s", tt.maxRisk, tt.bottleneck, placement.MaxRisk, placement.Bottleneck)
+			}
+			for id, count := range tt.expected {
+				if placement.Loads[id].Fragments != count {
+					t.Errorf("Expected %d fragments in %s, but got %d", count, id, placement.Loads[id].Fragments)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeAcrossDomains_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		domains   []FailureDomain
+		fragments int
+		expected  error
+	}{
+		{"Empty", nil, 3, ErrNoDataCenters},
+		{"DuplicateID", []FailureDomain{{ID: "eu", Risk: 1, MaxFragments: Unlimited, Children: []FailureDomain{{ID: "eu", Risk: 2, MaxFragments: Unlimited}}}}, 3, ErrInvalidDataCenterID},
+		{"NonPositiveRisk", []FailureDomain{{ID: "eu", Risk: 1, MaxFragments: Unlimited, Children: []FailureDomain{{ID: "fra", Risk: 0, MaxFragments: Unlimited}}}}, 3, ErrNonPositiveRisk},
+		{"RegionQuota", []FailureDomain{{ID: "eu", Risk: 1, MaxFragments: 2, Children: []FailureDomain{{ID: "fra", Risk: 2, MaxFragments: Unlimited}}}}, 3, ErrInsufficientCapacity},
+		{"Overflow", []FailureDomain{{ID: "eu", Risk: 1000, MaxFragments: Unlimited, Children: []FailureDomain{{ID: "fra", Risk: 2, MaxFragments: Unlimited}}}}, 7, ErrRiskOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := distributeAcrossDomains(tt.domains, tt.fragments)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
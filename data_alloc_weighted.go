@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const ErrNonPositiveWeight allocationError = "Error: fragment weight must be positive."
+
+// WeightedPlacement is a placement of fragments with different sizes.
+type WeightedPlacement struct {
+	NamedPlacement          // Cost of every load is the model cost of its Weight
+	Assignment     []string // data center ID of every fragment, in input order
+}
+
+// distributeWeightedFragments places fragments of the given weights (sizes in any integer
+// unit), the risk of a data center is the model cost of its weighted load.
+// MaxFragments limits the number of fragments, not their weight. MinFragments can't be
+// promised by next-fit and is rejected with ErrInvalidCapacity.
+//
+// The exact problem is NP-hard (it contains bin packing), so this is an approximation.
+// The search finds the minimal bound R at which next-fit places everything: fragments
+// sorted by decreasing weight fill the least risky data centers, and a data center takes
+// fragments while its load is below its weight capacity under R. Any exact placement needs
+// at least the bound at which capacities sum up to the total weight, and next-fit always
+// succeeds there, so R never exceeds the optimum R*. A data center stops right after
+// reaching its capacity, so every load exceeds its capacity under R* by less than the
+// largest weight: cost <= model.Cost(risk, capacity(R*) + maxWeight - 1).
+// With unit weights that is exact. Quotas may push next-fit past R*, the bound is only
+// guaranteed without them.
+func distributeWeightedFragments(dataCenters []DataCenter, weights []int, model RiskModel) (WeightedPlacement, error) {
+	if err := validateDataCenters(dataCenters); err != nil {
+		return WeightedPlacement{}, err
+	}
+	quota, unlimited := 0, false
+	for _, dc := range dataCenters {
+		if dc.MinFragments > 0 {
+			return WeightedPlacement{}, fmt.Errorf("%w: data center %s has minimum %d, weighted placement has no minimums", ErrInvalidCapacity, dc.ID, dc.MinFragments)
+		}
+		if dc.MaxFragments == Unlimited {
+			unlimited = true
+		} else {
+			quota += dc.MaxFragments
+		}
+	}
+	totalWeight := 0
+	for i, weight := range weights {
+		if weight <= 0 {
+			return WeightedPlacement{}, fmt.Errorf("%w: fragment %d weighs %d", ErrNonPositiveWeight, i, weight)
+		}
+		totalWeight += weight
+	}
+	if len(weights) > 0 && len(dataCenters) == 0 {
+		return WeightedPlacement{}, ErrNoDataCenters
+	}
+	if !unlimited && quota < len(weights) {
+		return WeightedPlacement{}, fmt.Errorf("%w: quota %d, %d to place", ErrInsufficientCapacity, quota, len(weights))
+	}
+
+	fragments := make([]int, len(weights))
+	for i := range fragments {
+		fragments[i] = i
+	}
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return weights[fragments[i]] > weights[fragments[j]]
+	})
+	dcOrder := riskOrder(risksOf(dataCenters))
+
+	nextFit := func(maxRisk int64, assignment []int) bool {
+		next := 0
+		for _, i := range dcOrder {
+			capacity, load := model.Capacity(dataCenters[i].Risk, maxRisk, totalWeight), 0
+			limit := dataCenters[i].MaxFragments
+			for count := 0; next < len(fragments) && load < capacity && (limit == Unlimited || count < limit); count, next = count+1, next+1 {
+				load += weights[fragments[next]]
+				if assignment != nil {
+					assignment[fragments[next]] = i
+				}
+			}
+		}
+		return next == len(fragments)
+	}
+
+	maxRisk, err := searchMinimalRisk(0, math.MaxInt64, func(maxRisk int64) bool {
+		return nextFit(maxRisk, nil)
+	})
+	if err != nil {
+		return WeightedPlacement{}, err
+	}
+
+	assignment := make([]int, len(weights))
+	nextFit(maxRisk, assignment)
+
+	placement := Placement{Loads: make([]DataCenterLoad, len(dataCenters)), Bottleneck: -1}
+	for i, dc := range dataCenters {
+		placement.Loads[i] = DataCenterLoad{Index: i, Risk: dc.Risk}
+	}
+	result := WeightedPlacement{Assignment: make([]string, len(weights))}
+	for fragment, i := range assignment {
+		placement.Loads[i].Fragments++
+		placement.Loads[i].Weight += weights[fragment]
+		result.Assignment[fragment] = dataCenters[i].ID
+	}
+	for i := range placement.Loads {
+		load := &placement.Loads[i]
+		cost, ok := model.Cost(load.Risk, load.Weight)
+		if !ok {
+			return WeightedPlacement{}, fmt.Errorf("%w: data center %s with load %d", ErrRiskOverflow, dataCenters[i].ID, load.Weight)
+		}
+		load.Cost = cost
+		if load.Fragments > 0 && cost > placement.MaxRisk {
+			placement.MaxRisk, placement.Bottleneck = cost, i
+		}
+	}
+
+	result.NamedPlacement = namePlacement(dataCenters, placement)
+	return result, nil
+}
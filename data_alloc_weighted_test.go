@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistributeWeightedFragments(t *testing.T) {
+	t.Run("UnitWeightsAreExact", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 10, MaxFragments: Unlimited}, {ID: "ams", Risk: 30, MaxFragments: Unlimited}, {ID: "lon", Risk: 20, MaxFragments: Unlimited}}
+		for _, model := range []RiskModel{ExponentialRisk{}, LinearRisk{}, QuadraticRisk{}} {
+			expected, _ := distributeModeledFragments(dataCenters, 5, model)
+			placement, err := distributeWeightedFragments(dataCenters, []int{1, 1, 1, 1, 1}, model)
+			if err != nil || placement.MaxRisk != expected.MaxRisk {
+				t.Errorf("%T: Expected %d, but got %d, %v", model, expected.MaxRisk, placement.MaxRisk, err)
+			}
+		}
+	})
+
+	t.Run("Linear", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 1, MaxFragments: Unlimited}, {ID: "ams", Risk: 2, MaxFragments: Unlimited}}
+		placement, err := distributeWeightedFragments(dataCenters, []int{4, 3, 3}, LinearRisk{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.MaxRisk != 7 || placement.Bottleneck != "fra" {
+			t.Errorf("Expected 7 in fra, but got %d in %s", placement.MaxRisk, placement.Bottleneck)
+		}
+		expected := []string{"fra", "fra", "ams"}
+		for i := range expected {
+			if placement.Assignment[i] != expected[i] {
+				t.Errorf("Expected %v, but got %v", expected, placement.Assignment)
+			}
+		}
+		if placement.Loads["fra"].Weight != 7 || placement.Loads["ams"].Weight != 3 {
+			t.Errorf("Expected loads 7 and 3, but got %+v", placement.Loads)
+		}
+		if placement.Loads["fra"].Fragments != 2 || placement.Loads["ams"].Fragments != 1 {
+			t.Errorf("Expected 2 and 1 fragments, but got %+v", placement.Loads)
+		}
+	})
+
+	t.Run("WithinBound", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "a", Risk: 3, MaxFragments: Unlimited}, {ID: "b", Risk: 5, MaxFragments: Unlimited}, {ID: "c", Risk: 7, MaxFragments: Unlimited}, {ID: "d", Risk: 11, MaxFragments: Unlimited}}
+		weights := []int{17, 3, 9, 1, 12, 12, 5, 8, 2, 30, 4}
+		placement, err := distributeWeightedFragments(dataCenters, weights, LinearRisk{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// fractional lower bound: the smallest R with sum(R/risk) >= total weight
+		fractional := func(maxRisk int64) int {
+			return boundedCapacity(dataCenters, maxRisk, 1000, LinearRisk{})
+		}
+		lowerBound := int64(0)
+		for fractional(lowerBound) < 103 {
+			lowerBound++
+		}
+		for id, load := range placement.Loads {
+			capacity := LinearRisk{}.Capacity(load.Risk, lowerBound, 1000)
+			if load.Weight >= capacity+30 {
+				t.Errorf("%s: load %d exceeds capacity %d by the largest weight", id, load.Weight, capacity)
+			}
+		}
+	})
+
+	t.Run("MaxFragments", func(t *testing.T) {
+		dataCenters := []DataCenter{{ID: "fra", Risk: 1, MaxFragments: 1}, {ID: "ams", Risk: 2, MaxFragments: Unlimited}}
+		placement, err := distributeWeightedFragments(dataCenters, []int{4, 3, 3}, LinearRisk{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if placement.Loads["fra"].Fragments != 1 || placement.Loads["ams"].Fragments != 2 || placement.MaxRisk != 12 {
+			t.Errorf("Expected 1 fragment in fra and 12 in ams, but got %+v", placement.Loads)
+		}
+	})
+
+	t.Run("Quotas", func(t *testing.T) {
+		cases := []struct {
+			name        string
+			dataCenters []DataCenter
+			expected    error
+		}{
+			{"InsufficientCapacity", []DataCenter{{ID: "fra", Risk: 1, MaxFragments: 1}, {ID: "ams", Risk: 2, MaxFragments: 1}}, ErrInsufficientCapacity},
+			{"MinFragments", []DataCenter{{ID: "fra", Risk: 1, MinFragments: 1, MaxFragments: Unlimited}}, ErrInvalidCapacity},
+		}
+		for _, c := range cases {
+			if _, err := distributeWeightedFragments(c.dataCenters, []int{4, 3, 3}, LinearRisk{}); !errors.Is(err, c.expected) {
+				t.Errorf("%s: Expected %v, but got %v", c.name, c.expected, err)
+			}
+		}
+	})
+
+	t.Run("NonPositiveWeight", func(t *testing.T) {
+		_, err := distributeWeightedFragments([]DataCenter{{ID: "fra", Risk: 1, MaxFragments: Unlimited}}, []int{3, 0}, LinearRisk{})
+		if !errors.Is(err, ErrNonPositiveWeight) {
+			t.Errorf("Expected %v, but got %v", ErrNonPositiveWeight, err)
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		_, err := distributeWeightedFragments([]DataCenter{{ID: "fra", Risk: 2, MaxFragments: Unlimited}}, []int{70}, ExponentialRisk{})
+		if !errors.Is(err, ErrRiskOverflow) {
+			t.Errorf("Expected %v, but got %v", ErrRiskOverflow, err)
+		}
+	})
+}
@@ -6,6 +6,14 @@ import (
 	"math"
 )
 
+type routingError string
+
+func (e routingError) Error() string {
+	return string(e)
+}
+
+const ErrInvalidCompressionProfile routingError = "Error: compression profile must have Ratio > 0 and Overhead >= 0."
+
 type Router struct {
 	liter   string
 	latency float64
@@ -14,7 +22,45 @@ type Router struct {
 type State struct {
 	liter   string
 	latency float64
-	path    string // just for visualisation and comfort
+	hop     *routeHop // last hop of the way here, nil at the source
+
+	compressions int // compressed hops so far, only counted by budgeted searches
+}
+
+// Hop is a single link of a route.
+type Hop struct {
+	From, To   string
+	Latency    float64 // raw link latency
+	Effective  float64 // latency after compression
+	Compressed bool    // From is a compression node
+}
+
+// Route is a way from source to destination.
+type Route struct {
+	Routers []string // source first, nil if unreachable
+	Hops    []Hop
+	Latency float64 // total effective latency, +Inf if unreachable
+}
+
+// routeHop chains hops back to the source, queued states share their common prefix.
+type routeHop struct {
+	Hop
+	prev *routeHop
+}
+
+// newRoute unwinds the hop chain ending at destination.
+func newRoute(source string, last *routeHop, latency float64) Route {
+	var hops []Hop
+	for hop := last; hop != nil; hop = hop.prev {
+		hops = append(hops, hop.Hop)
+	}
+
+	route := Route{Routers: []string{source}, Hops: make([]Hop, len(hops)), Latency: latency}
+	for i := range hops {
+		route.Hops[i] = hops[len(hops)-1-i]
+		route.Routers = append(route.Routers, route.Hops[i].To)
+	}
+	return route
 }
 
 type PriorityQueue []State
@@ -42,49 +88,136 @@ func findMinimumLatencyPath(
 	compressionNodes []string,
 	source, destination string,
 ) float64 {
-	var (
-		compressedSet = make(map[string]struct{})
-		latencyMap    = make(map[string]float64)
-	)
-	for _, node := range compressionNodes {
-		compressedSet[node] = struct{}{}
+	return findMinimumLatencyRoute(graph, compressionNodes, source, destination).Latency
+}
+
+// CompressionProfile is how a compression node changes its outgoing hops.
+// Ratio must be set, a zero profile is rejected rather than compressing hops to nothing.
+type CompressionProfile struct {
+	Ratio      float64 // compressed latency share, 0.7 is a 30% reduction
+	Overhead   float64 // fixed encode latency added to every compressed hop
+	MinLatency float64 // shorter links are passed through uncompressed
+}
+
+// DefaultCompressionProfile halves every hop.
+var DefaultCompressionProfile = CompressionProfile{Ratio: 0.5}
+
+// apply is the effective latency of a link leaving the node. Links are compressed only if
+// that makes them faster.
+func (p CompressionProfile) apply(latency float64) (effective float64, compressed bool) {
+	if latency < p.MinLatency {
+		return latency, false
+	}
+	if effective = latency*p.Ratio + p.Overhead; effective >= latency {
+		return latency, false
 	}
+	return effective, true
+}
+
+func validateProfiles(profiles map[string]CompressionProfile) error {
+	for node, p := range profiles {
+		if !(p.Ratio > 0) || !(p.Overhead >= 0) || math.IsNaN(p.MinLatency) {
+			return fmt.Errorf("%w: router %s has %+v", ErrInvalidCompressionProfile, node, p)
+		}
+	}
+	return nil
+}
 
-	for node := range graph {
-		latencyMap[node] = math.Inf(1)
+// defaultProfiles gives every compression node the default profile.
+func defaultProfiles(compressionNodes []string) map[string]CompressionProfile {
+	profiles := make(map[string]CompressionProfile, len(compressionNodes))
+	for _, node := range compressionNodes {
+		profiles[node] = DefaultCompressionProfile
 	}
+	return profiles
+}
 
-	latencyMap[source] = 0
+// findMinimumLatencyRoute is findMinimumLatencyPath returning the route itself.
+func findMinimumLatencyRoute(
+	graph map[string][]Router,
+	compressionNodes []string,
+	source, destination string,
+) Route {
+	route, _ := findProfiledLatencyRoute(graph, defaultProfiles(compressionNodes), source, destination) // the default is valid
+	return route
+}
 
-	queue := &PriorityQueue{}
-	heap.Init(queue)
-	heap.Push(queue, State{liter: source, latency: 0, path: source})
+// findProfiledLatencyRoute is findMinimumLatencyRoute where every compression node
+// compresses its outgoing hops by its own profile.
+// Latencies must be non-negative: every router is settled once, in order of its latency,
+// and the search stops as soon as the destination is settled.
+func findProfiledLatencyRoute(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source, destination string,
+) (Route, error) {
+	if err := validateProfiles(profiles); err != nil {
+		return Route{}, err
+	}
+	return searchRoute(graph, profiles, source, State{liter: source}, destination, nil, nil), nil
+}
 
+// routerLink is a link between two routers, parallel links are the same routerLink.
+type routerLink struct {
+	from, to string
+}
+
+// searchRoute continues the route to start towards destination, never using blocked
+// routers and links. start carries the way from source, so the route found is complete.
+func searchRoute(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source string,
+	start State,
+	destination string,
+	blockedRouters map[string]struct{},
+	blockedLinks map[routerLink]struct{},
+) Route {
 	var (
-		minLatency = math.Inf(1)
-		bestPath   string
+		latencyMap = make(map[string]float64) // best known latency, missing means +Inf
+		settled    = make(map[string]struct{}, len(blockedRouters))
 	)
+	for router := range blockedRouters {
+		settled[router] = struct{}{} // never reached, so never passed through
+	}
+
+	latencyMap[start.liter] = start.latency
+
+	queue := &PriorityQueue{}
+	heap.Init(queue)
+	heap.Push(queue, start)
 
 	for queue.Len() > 0 {
 		current := heap.Pop(queue).(State)
 
-		// find a best latency for every possible way
+		// a router is queued again on every improvement, only the first pop counts
+		if _, ok := settled[current.liter]; ok {
+			continue
+		}
+		settled[current.liter] = struct{}{}
+
 		if current.liter == destination {
-			if current.latency < minLatency {
-				minLatency = current.latency
-				bestPath = current.path
-			}
+			return newRoute(source, current.hop, current.latency)
 		}
 
+		profile, compressible := profiles[current.liter]
 		for _, router := range graph[current.liter] {
-			newPath := current.path + router.liter
-
-			newLatency := current.latency + router.latency
+			if _, ok := settled[router.liter]; ok {
+				continue
+			}
+			if _, ok := blockedLinks[routerLink{current.liter, router.liter}]; ok {
+				continue
+			}
 
-			if _, ok := compressedSet[current.liter]; ok {
-				newLatency = current.latency + router.latency/2 // compress new hop
+			effective, compressed := router.latency, false
+			if compressible {
+				effective, compressed = profile.apply(router.latency)
 			}
+			newLatency := current.latency + effective
 
+			if best, ok := latencyMap[router.liter]; ok && newLatency >= best {
+				continue
+			}
 			latencyMap[router.liter] = newLatency
 
 			heap.Push(
@@ -92,17 +225,14 @@ func findMinimumLatencyPath(
 				State{
 					liter:   router.liter,
 					latency: newLatency,
-					path:    newPath,
+					hop: &routeHop{
+						Hop:  Hop{From: current.liter, To: router.liter, Latency: router.latency, Effective: effective, Compressed: compressed},
+						prev: current.hop,
+					},
 				},
 			)
-
 		}
 	}
 
-	if minLatency == math.Inf(1) {
-		return math.Inf(1)
-	}
-
-	fmt.Println("BEST PATH", bestPath)
-	return minLatency
+	return Route{Latency: math.Inf(1)}
 }
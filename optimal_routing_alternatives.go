@@ -0,0 +1,353 @@
+package main
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"slices"
+)
+
+// Disjointness is what alternative routes must not share.
+type Disjointness int
+
+const (
+	AnyRoutes    Disjointness = iota // routes differ in at least one hop
+	LinkDisjoint                     // no link is used by two routes
+	NodeDisjoint                     // no router but source and destination is used by two routes
+)
+
+// findAlternativeRoutes is findMinimumLatencyRoute returning up to k loopless routes,
+// fastest first. Fewer if there aren't that many, nil if destination is unreachable.
+func findAlternativeRoutes(
+	graph map[string][]Router,
+	compressionNodes []string,
+	source, destination string,
+	k int,
+	disjointness Disjointness,
+) []Route {
+	routes, _ := findProfiledAlternativeRoutes(graph, defaultProfiles(compressionNodes), source, destination, k, disjointness) // the default is valid
+	return routes
+}
+
+// findProfiledAlternativeRoutes is findAlternativeRoutes with compression profiles.
+// AnyRoutes are the k fastest routes by Yen's algorithm. Disjoint routes are as many as
+// exist up to k with the smallest total latency (Suurballe/Bhandari), so the fastest
+// route alone may not be among them when it would block a second one.
+func findProfiledAlternativeRoutes(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source, destination string,
+	k int,
+	disjointness Disjointness,
+) ([]Route, error) {
+	if err := validateProfiles(profiles); err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+	if disjointness == AnyRoutes {
+		return yenRoutes(graph, profiles, source, destination, k), nil
+	}
+	if source == destination { // there's no other way
+		return []Route{{Routers: []string{source}, Latency: 0}}, nil
+	}
+	return disjointRoutes(graph, profiles, source, destination, k, disjointness), nil
+}
+
+// flowEdge is an edge of the residual network. Routers are split into an in and an out
+// node joined by an edge, its capacity limits how many routes pass the router.
+type flowEdge struct {
+	to, rev  int // rev is the index of the reverse edge in the edges of to
+	capacity int
+	cost     float64
+	forward  bool // false for reverse edges
+	flow     int  // routes using a forward edge, reverse edges keep it zero
+	hop      *Hop // link behind a forward link edge, nil otherwise
+}
+
+type flowNetwork struct {
+	edges [][]flowEdge
+}
+
+func (n *flowNetwork) add(from, to, capacity int, cost float64, hop *Hop) {
+	n.edges[from] = append(n.edges[from], flowEdge{to: to, rev: len(n.edges[to]), capacity: capacity, cost: cost, forward: true, hop: hop})
+	n.edges[to] = append(n.edges[to], flowEdge{to: from, rev: len(n.edges[from]) - 1, cost: -cost})
+}
+
+// disjointRoutes sends up to k units of min-cost flow from source to destination, one
+// shortest augmenting route at a time, and splits the flow into routes. Every link takes
+// one unit, so does every router in between for NodeDisjoint. Parallel links are one
+// link, the fastest of them.
+func disjointRoutes(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source, destination string,
+	k int,
+	disjointness Disjointness,
+) []Route {
+	index := map[string]int{source: 0, destination: 1}
+	names := []string{source, destination}
+	lookup := func(router string) int {
+		i, ok := index[router]
+		if !ok {
+			i = len(names)
+			index[router] = i
+			names = append(names, router)
+		}
+		return i
+	}
+
+	froms := make([]string, 0, len(graph))
+	for from := range graph {
+		froms = append(froms, from)
+	}
+	slices.Sort(froms) // equal total latencies must not depend on map order
+
+	type link struct {
+		from, to int
+		hop      Hop
+	}
+	var links []link
+	for _, from := range froms {
+		if from == destination {
+			continue
+		}
+		profile, compressible := profiles[from]
+		fastest := make(map[string]int) // router -> position in links
+		for _, router := range graph[from] {
+			if router.liter == from || router.liter == source {
+				continue // never on a loopless route
+			}
+			hop := Hop{From: from, To: router.liter, Latency: router.latency, Effective: router.latency}
+			if compressible {
+				hop.Effective, hop.Compressed = profile.apply(router.latency)
+			}
+			if i, ok := fastest[router.liter]; ok {
+				if hop.Effective < links[i].hop.Effective {
+					links[i].hop = hop
+				}
+				continue
+			}
+			fastest[router.liter] = len(links)
+			links = append(links, link{from: lookup(from), to: lookup(router.liter), hop: hop})
+		}
+	}
+
+	// router i is in node 2i and out node 2i+1
+	network := flowNetwork{edges: make([][]flowEdge, 2*len(names))}
+	for i := range names {
+		capacity := k
+		if disjointness == NodeDisjoint && i > 1 {
+			capacity = 1
+		}
+		network.add(2*i, 2*i+1, capacity, 0, nil)
+	}
+	for i := range links {
+		network.add(2*links[i].from+1, 2*links[i].to, 1, links[i].hop.Effective, &links[i].hop)
+	}
+
+	var (
+		from, to  = 1, 2 // out of source, in of destination
+		potential = make([]float64, len(network.edges))
+		flow      int
+	)
+	for flow < k && network.augment(from, to, potential) {
+		flow++
+	}
+
+	routes := make([]Route, 0, flow)
+	for ; flow > 0; flow-- {
+		routes = append(routes, network.takeRoute(from, to, source))
+	}
+	slices.SortStableFunc(routes, func(a, b Route) int {
+		return cmp.Compare(a.Latency, b.Latency)
+	})
+	return routes
+}
+
+// augment pushes a unit of flow along the shortest residual route. Costs are reduced by
+// potentials (Johnson), so they stay non-negative for Dijkstra despite reverse edges.
+func (n *flowNetwork) augment(from, to int, potential []float64) bool {
+	type previous struct{ node, edge int }
+	var (
+		dist  = make([]float64, len(n.edges))
+		prev  = make([]previous, len(n.edges))
+		done  = make([]bool, len(n.edges))
+		queue = &flowQueue{{from, 0}}
+	)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+	}
+	dist[from] = 0
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(flowItem)
+		if done[current.node] {
+			continue
+		}
+		done[current.node] = true
+
+		for i, edge := range n.edges[current.node] {
+			if edge.capacity == 0 || done[edge.to] {
+				continue
+			}
+			reduced := max(edge.cost+potential[current.node]-potential[edge.to], 0) // rounding only
+			if next := dist[current.node] + reduced; next < dist[edge.to] {
+				dist[edge.to] = next
+				prev[edge.to] = previous{current.node, i}
+				heap.Push(queue, flowItem{edge.to, next})
+			}
+		}
+	}
+	if math.IsInf(dist[to], 1) {
+		return false
+	}
+
+	for node := range potential {
+		if !math.IsInf(dist[node], 1) {
+			potential[node] += dist[node]
+		}
+	}
+	for node := to; node != from; node = prev[node].node {
+		edge := &n.edges[prev[node].node][prev[node].edge]
+		reverse := &n.edges[edge.to][edge.rev]
+		edge.capacity--
+		reverse.capacity++
+		if edge.forward {
+			edge.flow++
+		} else {
+			reverse.flow-- // pushing back cancels flow of the forward edge
+		}
+	}
+	return true
+}
+
+// takeRoute follows a unit of flow from source to destination and removes it. Zero latency
+// links may leave a cycle in the flow, it is cut out of the route.
+func (n *flowNetwork) takeRoute(from, to int, source string) Route {
+	var (
+		hops []Hop
+		seen = map[string]int{source: 0} // router -> hops before it
+	)
+	for node := from; node != to; {
+		for i := range n.edges[node] {
+			edge := &n.edges[node][i]
+			if !edge.forward || edge.flow == 0 {
+				continue
+			}
+			edge.flow--
+			node = edge.to
+			if edge.hop == nil {
+				break
+			}
+			if at, ok := seen[edge.hop.To]; ok {
+				hops = hops[:at]
+				for router, before := range seen {
+					if before > at {
+						delete(seen, router)
+					}
+				}
+			} else {
+				hops = append(hops, *edge.hop)
+			}
+			seen[edge.hop.To] = len(hops)
+			break
+		}
+	}
+
+	route := Route{Routers: []string{source}, Hops: hops}
+	for _, hop := range hops {
+		route.Routers = append(route.Routers, hop.To)
+		route.Latency += hop.Effective
+	}
+	return route
+}
+
+type flowItem struct {
+	node int
+	dist float64
+}
+
+type flowQueue []flowItem
+
+func (q flowQueue) Len() int            { return len(q) }
+func (q flowQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q flowQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *flowQueue) Push(x interface{}) { *q = append(*q, x.(flowItem)) }
+func (q *flowQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// yenRoutes finds every next route as a detour from some router of the previous one:
+// the way up to that router is kept, the links the routes found so far take from it
+// are blocked, so are the routers before it to keep the detour loopless.
+func yenRoutes(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source, destination string,
+	k int,
+) []Route {
+	first := searchRoute(graph, profiles, source, State{liter: source}, destination, nil, nil)
+	if first.Routers == nil {
+		return nil
+	}
+
+	var (
+		routes     = []Route{first}
+		candidates []Route // detours not taken yet, in order of discovery
+	)
+	for len(routes) < k {
+		previous := routes[len(routes)-1]
+
+		root := State{liter: source}
+		for i, hop := range previous.Hops {
+			blockedRouters := make(map[string]struct{}, i)
+			for _, router := range previous.Routers[:i] {
+				blockedRouters[router] = struct{}{}
+			}
+			blockedLinks := make(map[routerLink]struct{})
+			for _, route := range routes {
+				if len(route.Hops) > i && slices.Equal(route.Routers[:i+1], previous.Routers[:i+1]) {
+					blockedLinks[routerLink{route.Hops[i].From, route.Hops[i].To}] = struct{}{}
+				}
+			}
+
+			detour := searchRoute(graph, profiles, source, root, destination, blockedRouters, blockedLinks)
+			if detour.Routers != nil && !containsRoute(candidates, detour) && !containsRoute(routes, detour) {
+				candidates = append(candidates, detour)
+			}
+
+			root = State{
+				liter:   hop.To,
+				latency: root.latency + hop.Effective,
+				hop:     &routeHop{Hop: hop, prev: root.hop},
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		best := 0
+		for i, candidate := range candidates {
+			if candidate.Latency < candidates[best].Latency {
+				best = i
+			}
+		}
+		routes = append(routes, candidates[best])
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+	return routes
+}
+
+func containsRoute(routes []Route, route Route) bool {
+	for _, r := range routes {
+		if slices.Equal(r.Routers, route.Routers) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func yenGraph() map[string][]Router {
+	return map[string][]Router{
+		"C": {{"D", 3}, {"E", 2}},
+		"D": {{"F", 4}},
+		"E": {{"D", 1}, {"F", 2}, {"G", 3}},
+		"F": {{"G", 2}, {"H", 1}},
+		"G": {{"H", 2}},
+		"H": {},
+	}
+}
+
+func TestFindAlternativeRoutes(t *testing.T) {
+	tests := []struct {
+		name             string
+		compressionNodes []string
+		disjointness     Disjointness
+		expectedRouters  [][]string
+		expectedLatency  []float64
+	}{
+		{
+			name:            "Any routes",
+			disjointness:    AnyRoutes,
+			expectedRouters: [][]string{{"C", "E", "F", "H"}, {"C", "E", "G", "H"}, {"C", "D", "F", "H"}},
+			expectedLatency: []float64{5, 7, 8},
+		},
+		{
+			name:             "Any routes with compression",
+			compressionNodes: []string{"D"},
+			disjointness:     AnyRoutes,
+			expectedRouters:  [][]string{{"C", "E", "F", "H"}, {"C", "D", "F", "H"}, {"C", "E", "D", "F", "H"}},
+			expectedLatency:  []float64{5, 6, 6},
+		},
+		{
+			// C-E-F-H with C-D-F-G-H takes 16
+			name:            "Link disjoint",
+			disjointness:    LinkDisjoint,
+			expectedRouters: [][]string{{"C", "E", "G", "H"}, {"C", "D", "F", "H"}},
+			expectedLatency: []float64{7, 8},
+		},
+		{
+			// C-E-F-H leaves no route avoiding E and F
+			name:            "Node disjoint",
+			disjointness:    NodeDisjoint,
+			expectedRouters: [][]string{{"C", "E", "G", "H"}, {"C", "D", "F", "H"}},
+			expectedLatency: []float64{7, 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := findAlternativeRoutes(yenGraph(), tt.compressionNodes, "C", "H", 3, tt.disjointness)
+			var (
+				routers []string
+				latency []float64
+			)
+			for _, route := range routes {
+				routers = append(routers, route.Routers...)
+				latency = append(latency, route.Latency)
+			}
+			if !reflect.DeepEqual(routers, slices.Concat(tt.expectedRouters...)) || !reflect.DeepEqual(latency, tt.expectedLatency) {
+				t.Errorf("expected %v %v, got %+v", tt.expectedRouters, tt.expectedLatency, routes)
+			}
+		})
+	}
+
+	t.Run("No routes", func(t *testing.T) {
+		if routes := findAlternativeRoutes(yenGraph(), nil, "H", "C", 3, AnyRoutes); routes != nil {
+			t.Errorf("expected no routes, got %+v", routes)
+		}
+		if routes := findAlternativeRoutes(yenGraph(), nil, "C", "H", 0, AnyRoutes); routes != nil {
+			t.Errorf("expected no routes, got %+v", routes)
+		}
+	})
+
+	t.Run("Source equals destination", func(t *testing.T) {
+		for _, disjointness := range []Disjointness{AnyRoutes, LinkDisjoint, NodeDisjoint} {
+			routes := findAlternativeRoutes(yenGraph(), nil, "C", "C", 3, disjointness)
+			if len(routes) != 1 || len(routes[0].Hops) != 0 {
+				t.Errorf("expected a single empty route, got %+v", routes)
+			}
+		}
+	})
+}
+
+func TestFindAlternativeRoutes_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 30; i++ {
+		graph := randomNetwork(rnd, 8, 20)
+		compressionNodes := []string{strconv.Itoa(rnd.Intn(8)), strconv.Itoa(rnd.Intn(8))}
+		source, destination := strconv.Itoa(rnd.Intn(8)), strconv.Itoa(rnd.Intn(8))
+
+		simple := simpleRoutes(graph, compressionNodes, source, destination)
+		routes := findAlternativeRoutes(graph, compressionNodes, source, destination, 1000, AnyRoutes)
+		if len(routes) != len(simple) {
+			t.Fatalf("%s -> %s: expected %d routes, got %d", source, destination, len(simple), len(routes))
+		}
+		for j, route := range routes {
+			if route.Latency != simple[j].latency {
+				t.Fatalf("%s -> %s: expected route %d latency %.2f, got %.2f", source, destination, j, simple[j].latency, route.Latency)
+			}
+			if len(uniqueRouters(route.Routers)) != len(route.Routers) {
+				t.Fatalf("%s -> %s: route %v has a loop", source, destination, route.Routers)
+			}
+		}
+
+		for _, disjointness := range []Disjointness{LinkDisjoint, NodeDisjoint} {
+			const k = 3
+			routes := findAlternativeRoutes(graph, compressionNodes, source, destination, k, disjointness)
+
+			// as many routes as exist, with the smallest total
+			count, total := bestDisjointRoutes(simple, k, disjointness, destination)
+			latency := 0.0
+			for _, route := range routes {
+				latency += route.Latency
+			}
+			if len(routes) != count || math.Abs(latency-total) > 1e-9 {
+				t.Fatalf("%s -> %s: expected %d routes taking %.2f, got %d taking %.2f",
+					source, destination, count, total, len(routes), latency)
+			}
+
+			used := make(map[string]int)
+			for _, route := range routes {
+				if len(uniqueRouters(route.Routers)) != len(route.Routers) || route.Routers[len(route.Routers)-1] != destination {
+					t.Fatalf("%s -> %s: broken route %v", source, destination, route.Routers)
+				}
+				for _, hop := range route.Hops {
+					used[hop.From+">"+hop.To]++
+					if disjointness == NodeDisjoint && hop.To != destination {
+						used[hop.To]++
+					}
+				}
+			}
+			for shared, count := range used {
+				if count > 1 {
+					t.Fatalf("%s -> %s: %s is used by %d routes", source, destination, shared, count)
+				}
+			}
+		}
+	}
+}
+
+type simpleRoute struct {
+	routers []string
+	latency float64
+}
+
+// simpleRoutes is every loopless route, fastest first.
+// Parallel links between the same routers make one route, the fastest of them.
+func simpleRoutes(graph map[string][]Router, compressionNodes []string, source, destination string) []simpleRoute {
+	compressed := make(map[string]bool)
+	for _, node := range compressionNodes {
+		compressed[node] = true
+	}
+
+	var (
+		routes  []simpleRoute
+		visited = map[string]bool{source: true}
+		path    = []string{source}
+	)
+	var walk func(at string, latency float64)
+	walk = func(at string, latency float64) {
+		if at == destination {
+			routes = append(routes, simpleRoute{slices.Clone(path), latency})
+			return
+		}
+
+		fastest := make(map[string]float64)
+		for _, router := range graph[at] {
+			effective := router.latency
+			if compressed[at] {
+				effective = router.latency / 2
+			}
+			if best, ok := fastest[router.liter]; !ok || effective < best {
+				fastest[router.liter] = effective
+			}
+		}
+		for next, effective := range fastest {
+			if !visited[next] {
+				visited[next] = true
+				path = append(path, next)
+				walk(next, latency+effective)
+				path = path[:len(path)-1]
+				visited[next] = false
+			}
+		}
+	}
+	walk(source, 0)
+
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].latency < routes[j].latency })
+	return routes
+}
+
+// bestDisjointRoutes tries every set of up to k routes, the largest one with the smallest
+// total wins.
+func bestDisjointRoutes(routes []simpleRoute, k int, disjointness Disjointness, destination string) (count int, total float64) {
+	used := make(map[string]bool)
+	parts := func(route simpleRoute) []string {
+		var keys []string
+		for i := 1; i < len(route.routers); i++ {
+			keys = append(keys, route.routers[i-1]+">"+route.routers[i])
+			if disjointness == NodeDisjoint && route.routers[i] != destination {
+				keys = append(keys, route.routers[i])
+			}
+		}
+		return keys
+	}
+
+	var try func(from, taken int, latency float64)
+	try = func(from, taken int, latency float64) {
+		if taken > count || taken == count && latency < total {
+			count, total = taken, latency
+		}
+		if taken == k {
+			return
+		}
+		for i := from; i < len(routes); i++ {
+			keys := parts(routes[i])
+			if slices.ContainsFunc(keys, func(key string) bool { return used[key] }) {
+				continue
+			}
+			for _, key := range keys {
+				used[key] = true
+			}
+			try(i+1, taken+1, latency+routes[i].latency)
+			for _, key := range keys {
+				used[key] = false
+			}
+		}
+	}
+	try(0, 0, 0)
+	return count, total
+}
+
+func uniqueRouters(routers []string) map[string]struct{} {
+	unique := make(map[string]struct{}, len(routers))
+	for _, router := range routers {
+		unique[router] = struct{}{}
+	}
+	return unique
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+)
+
+// budgetState is a router reached with some compressions spent.
+type budgetState struct {
+	liter        string
+	compressions int
+}
+
+// findBudgetedLatencyRoutes is findMinimumLatencyRoute where a route compresses at most
+// maxCompressions hops, a compression node may also pass a hop through uncompressed to
+// save the budget for later. routes[b] is the best route with at most b compressions,
+// for every b from 0 to maxCompressions, so routes are never getting slower.
+// Nil if maxCompressions is negative.
+func findBudgetedLatencyRoutes(
+	graph map[string][]Router,
+	compressionNodes []string,
+	source, destination string,
+	maxCompressions int,
+) []Route {
+	routes, _ := findProfiledBudgetedRoutes(graph, defaultProfiles(compressionNodes), source, destination, maxCompressions) // the default is valid
+	return routes
+}
+
+// findProfiledBudgetedRoutes is findBudgetedLatencyRoutes with compression profiles.
+func findProfiledBudgetedRoutes(
+	graph map[string][]Router,
+	profiles map[string]CompressionProfile,
+	source, destination string,
+	maxCompressions int,
+) ([]Route, error) {
+	if err := validateProfiles(profiles); err != nil {
+		return nil, err
+	}
+	if maxCompressions < 0 {
+		return nil, nil
+	}
+
+	var (
+		latencyMap = make(map[budgetState]float64) // best known latency, missing means +Inf
+		settled    = make(map[budgetState]struct{})
+		routes     = make([]Route, maxCompressions+1)
+		found      = make([]bool, maxCompressions+1)
+	)
+
+	latencyMap[budgetState{source, 0}] = 0
+
+	queue := &PriorityQueue{}
+	heap.Init(queue)
+	heap.Push(queue, State{liter: source, latency: 0})
+
+	for queue.Len() > 0 && !found[0] {
+		current := heap.Pop(queue).(State)
+
+		key := budgetState{current.liter, current.compressions}
+		if _, ok := settled[key]; ok {
+			continue
+		}
+		settled[key] = struct{}{}
+
+		if current.liter == destination {
+			// states come out by latency, the first one within a budget is the best for it
+			for b := current.compressions; b <= maxCompressions && !found[b]; b++ {
+				routes[b], found[b] = newRoute(source, current.hop, current.latency), true
+			}
+			continue
+		}
+
+		profile, compressible := profiles[current.liter]
+		for _, router := range graph[current.liter] {
+			hop := Hop{From: current.liter, To: router.liter, Latency: router.latency, Effective: router.latency}
+			pushBudgetState(queue, latencyMap, settled, current, hop)
+
+			if !compressible || current.compressions >= maxCompressions {
+				continue
+			}
+			if effective, compressed := profile.apply(router.latency); compressed {
+				hop.Effective, hop.Compressed = effective, true
+				pushBudgetState(queue, latencyMap, settled, current, hop)
+			}
+		}
+	}
+
+	for b := range routes {
+		if !found[b] {
+			routes[b] = Route{Latency: math.Inf(1)}
+		}
+	}
+	return routes, nil
+}
+
+// pushBudgetState queues the state after hop unless it is known to be reached faster.
+func pushBudgetState(
+	queue *PriorityQueue,
+	latencyMap map[budgetState]float64,
+	settled map[budgetState]struct{},
+	current State,
+	hop Hop,
+) {
+	next := State{liter: hop.To, latency: current.latency + hop.Effective, compressions: current.compressions}
+	if hop.Compressed {
+		next.compressions++
+	}
+
+	key := budgetState{next.liter, next.compressions}
+	if _, ok := settled[key]; ok {
+		return
+	}
+	if best, ok := latencyMap[key]; ok && next.latency >= best {
+		return
+	}
+	latencyMap[key] = next.latency
+
+	next.hop = &routeHop{Hop: hop, prev: current.hop}
+	heap.Push(queue, next)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestFindBudgetedLatencyRoutes(t *testing.T) {
+	graph := map[string][]Router{
+		"A": {{"B", 2}, {"X", 1}},
+		"B": {{"C", 100}},
+		"C": {{"D", 100}},
+		"D": {},
+		"X": {{"D", 180}},
+	}
+	compressionNodes := []string{"A", "B", "C"}
+
+	routes := findBudgetedLatencyRoutes(graph, compressionNodes, "A", "D", 4)
+	expected := []float64{181, 152, 102, 101, 101}
+	for b, route := range routes {
+		if route.Latency != expected[b] {
+			t.Errorf("budget %d: expected latency %.2f, got %.2f", b, expected[b], route.Latency)
+		}
+	}
+
+	// the only compression is saved for the long B -> C hop
+	if want := []string{"A", "B", "C", "D"}; !reflect.DeepEqual(routes[1].Routers, want) {
+		t.Errorf("budget 1: expected route %v, got %v", want, routes[1].Routers)
+	}
+	if routes[1].Hops[0].Compressed || !routes[1].Hops[1].Compressed {
+		t.Errorf("budget 1: expected B -> C compressed, got %+v", routes[1].Hops)
+	}
+
+	t.Run("Unreachable destination", func(t *testing.T) {
+		routes := findBudgetedLatencyRoutes(graph, compressionNodes, "D", "A", 1)
+		if len(routes) != 2 || !math.IsInf(routes[0].Latency, 1) || !math.IsInf(routes[1].Latency, 1) {
+			t.Errorf("expected no routes, got %+v", routes)
+		}
+	})
+
+	t.Run("Negative budget", func(t *testing.T) {
+		if routes := findBudgetedLatencyRoutes(graph, compressionNodes, "A", "D", -1); routes != nil {
+			t.Errorf("expected nil, got %+v", routes)
+		}
+	})
+}
+
+func TestFindBudgetedLatencyRoutes_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 50; i++ {
+		graph := randomNetwork(rnd, 20, 80)
+		compressionNodes := make([]string, 0, 8)
+		for j := 0; j < 8; j++ {
+			compressionNodes = append(compressionNodes, strconv.Itoa(rnd.Intn(20)))
+		}
+		source, destination := strconv.Itoa(rnd.Intn(20)), strconv.Itoa(rnd.Intn(20))
+
+		// no compressions is the plain search, an unlimited budget is the usual one
+		routes := findBudgetedLatencyRoutes(graph, compressionNodes, source, destination, 20)
+		if plain := findMinimumLatencyPath(graph, nil, source, destination); routes[0].Latency != plain {
+			t.Fatalf("%s -> %s: expected latency %.2f without compressions, got %.2f", source, destination, plain, routes[0].Latency)
+		}
+		if best := findMinimumLatencyPath(graph, compressionNodes, source, destination); routes[20].Latency != best {
+			t.Fatalf("%s -> %s: expected latency %.2f, got %.2f", source, destination, best, routes[20].Latency)
+		}
+		for b := 1; b < len(routes); b++ {
+			if routes[b].Latency > routes[b-1].Latency {
+				t.Fatalf("%s -> %s: budget %d is slower than budget %d", source, destination, b, b-1)
+			}
+			compressed := 0
+			for _, hop := range routes[b].Hops {
+				if hop.Compressed {
+					compressed++
+				}
+			}
+			if compressed > b {
+				t.Fatalf("%s -> %s: budget %d route has %d compressions", source, destination, b, compressed)
+			}
+		}
+	}
+}
+
+func TestFindProfiledBudgetedRoutes(t *testing.T) {
+	graph := map[string][]Router{
+		"A": {{"B", 10}},
+		"B": {{"C", 15}},
+		"C": {{"D", 40}},
+		"D": {},
+	}
+	// compressing at B costs more than it saves, the budget goes to C
+	profiles := map[string]CompressionProfile{"B": {Ratio: 0.5, Overhead: 20}, "C": {Ratio: 0.7, Overhead: 2}}
+
+	routes, err := findProfiledBudgetedRoutes(graph, profiles, "A", "D", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []float64{65, 55, 55}
+	for b, route := range routes {
+		if route.Latency != expected[b] {
+			t.Errorf("budget %d: expected latency %.2f, got %.2f", b, expected[b], route.Latency)
+		}
+	}
+	if hops := routes[2].Hops; hops[1].Compressed || !hops[2].Compressed {
+		t.Errorf("expected only C -> D compressed, got %+v", hops)
+	}
+}
+
+func TestFindProfiledBudgetedRoutes_MatchesUnbudgeted(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	for i := 0; i < 50; i++ {
+		graph := randomNetwork(rnd, 20, 80)
+		profiles := make(map[string]CompressionProfile)
+		for j := 0; j < 8; j++ {
+			profiles[strconv.Itoa(rnd.Intn(20))] = CompressionProfile{
+				Ratio:      float64(1+rnd.Intn(9)) / 10,
+				Overhead:   float64(rnd.Intn(30)),
+				MinLatency: float64(rnd.Intn(50)),
+			}
+		}
+		source, destination := strconv.Itoa(rnd.Intn(20)), strconv.Itoa(rnd.Intn(20))
+
+		routes, _ := findProfiledBudgetedRoutes(graph, profiles, source, destination, 20)
+		if best, _ := findProfiledLatencyRoute(graph, profiles, source, destination); routes[20].Latency != best.Latency {
+			t.Fatalf("%s -> %s: expected latency %.2f, got %.2f", source, destination, best.Latency, routes[20].Latency)
+		}
+	}
+}
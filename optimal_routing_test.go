@@ -1,7 +1,11 @@
 package main
 
 import (
+	"errors"
 	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -99,3 +103,246 @@ func TestFindMinimumLatencyPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMinimumLatencyRoute(t *testing.T) {
+	graph := map[string][]Router{
+		"fra1":  {{"ams12", 10}, {"lon3", 20}},
+		"ams12": {{"par", 15}},
+		"lon3":  {{"par", 30}},
+		"par":   {},
+	}
+
+	route := findMinimumLatencyRoute(graph, []string{"ams12"}, "fra1", "par")
+	expected := Route{
+		Routers: []string{"fra1", "ams12", "par"},
+		Hops: []Hop{
+			{From: "fra1", To: "ams12", Latency: 10, Effective: 10},
+			{From: "ams12", To: "par", Latency: 15, Effective: 7.5, Compressed: true},
+		},
+		Latency: 17.5,
+	}
+	if !reflect.DeepEqual(route, expected) {
+		t.Errorf("expected route %+v, got %+v", expected, route)
+	}
+
+	t.Run("Source equals destination", func(t *testing.T) {
+		route := findMinimumLatencyRoute(graph, nil, "fra1", "fra1")
+		if route.Latency != 0 || len(route.Hops) != 0 || !reflect.DeepEqual(route.Routers, []string{"fra1"}) {
+			t.Errorf("expected empty route at fra1, got %+v", route)
+		}
+	})
+
+	t.Run("Unreachable destination", func(t *testing.T) {
+		route := findMinimumLatencyRoute(graph, nil, "par", "fra1")
+		if !math.IsInf(route.Latency, 1) || route.Routers != nil || route.Hops != nil {
+			t.Errorf("expected no route, got %+v", route)
+		}
+	})
+}
+
+func TestFindMinimumLatencyRoute_Cycles(t *testing.T) {
+	graph := map[string][]Router{
+		"A": {{"B", 1}},
+		"B": {{"A", 1}, {"C", 5}},
+		"C": {{"B", 5}, {"C", 0}},
+		"X": {},
+	}
+
+	if latency := findMinimumLatencyPath(graph, []string{"B"}, "A", "C"); latency != 3.5 {
+		t.Errorf("expected latency 3.50, got %.2f", latency)
+	}
+	if latency := findMinimumLatencyPath(graph, nil, "A", "X"); !math.IsInf(latency, 1) {
+		t.Errorf("expected unreachable X, got %.2f", latency)
+	}
+}
+
+func TestFindMinimumLatencyRoute_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		graph := randomNetwork(rnd, 30, 120)
+		compressionNodes := make([]string, 0, 10)
+		for j := 0; j < 10; j++ {
+			compressionNodes = append(compressionNodes, strconv.Itoa(rnd.Intn(30)))
+		}
+		source, destination := strconv.Itoa(rnd.Intn(30)), strconv.Itoa(rnd.Intn(30))
+
+		expected := bellmanFordLatency(graph, compressionNodes, source, destination)
+		route := findMinimumLatencyRoute(graph, compressionNodes, source, destination)
+		if route.Latency != expected {
+			t.Fatalf("%s -> %s: expected latency %.2f, got %.2f", source, destination, expected, route.Latency)
+		}
+		if math.IsInf(expected, 1) {
+			continue
+		}
+
+		// the hops must add up and lead from source to destination
+		total, at := 0.0, source
+		for _, hop := range route.Hops {
+			if hop.From != at {
+				t.Fatalf("%s -> %s: broken route %+v", source, destination, route.Routers)
+			}
+			total += hop.Effective
+			at = hop.To
+		}
+		if at != destination || total != route.Latency {
+			t.Errorf("%s -> %s: route %+v ends at %s with latency %.2f", source, destination, route.Routers, at, total)
+		}
+	}
+}
+
+func BenchmarkFindMinimumLatencyRoute(b *testing.B) {
+	const routers = 100000
+	rnd := rand.New(rand.NewSource(1))
+	graph := randomNetwork(rnd, routers, 1000000)
+
+	compressionNodes := make([]string, 0, routers/100)
+	for i := 0; i < routers; i += 100 {
+		compressionNodes = append(compressionNodes, strconv.Itoa(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		findMinimumLatencyRoute(graph, compressionNodes, strconv.Itoa(rnd.Intn(routers)), strconv.Itoa(rnd.Intn(routers)))
+	}
+}
+
+// randomNetwork links random routers named by numbers, cycles and self links included.
+func randomNetwork(rnd *rand.Rand, routers, links int) map[string][]Router {
+	names := make([]string, routers)
+	graph := make(map[string][]Router, routers)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+		graph[names[i]] = nil
+	}
+	for i := 0; i < links; i++ {
+		from := names[rnd.Intn(routers)]
+		graph[from] = append(graph[from], Router{names[rnd.Intn(routers)], float64(rnd.Intn(100))})
+	}
+	return graph
+}
+
+// bellmanFordLatency relaxes every link until nothing improves.
+func bellmanFordLatency(graph map[string][]Router, compressionNodes []string, source, destination string) float64 {
+	compressed := make(map[string]bool)
+	for _, node := range compressionNodes {
+		compressed[node] = true
+	}
+
+	latency := map[string]float64{source: 0}
+	for changed := true; changed; {
+		changed = false
+		for from, routers := range graph {
+			base, ok := latency[from]
+			if !ok {
+				continue
+			}
+			for _, router := range routers {
+				next := base + router.latency
+				if compressed[from] {
+					next = base + router.latency/2
+				}
+				if best, ok := latency[router.liter]; !ok || next < best {
+					latency[router.liter] = next
+					changed = true
+				}
+			}
+		}
+	}
+
+	if best, ok := latency[destination]; ok {
+		return best
+	}
+	return math.Inf(1)
+}
+
+func TestFindProfiledLatencyRoute(t *testing.T) {
+	graph := map[string][]Router{
+		"A": {{"B", 10}, {"C", 20}},
+		"B": {{"D", 15}},
+		"C": {{"D", 30}},
+		"D": {},
+	}
+
+	tests := []struct {
+		name            string
+		profiles        map[string]CompressionProfile
+		expectedLatency float64
+		expectedRouters []string
+		compressed      bool // whether the last hop is compressed
+	}{
+		{
+			name:            "Default profile",
+			profiles:        map[string]CompressionProfile{"B": DefaultCompressionProfile},
+			expectedLatency: 17.5,
+			expectedRouters: []string{"A", "B", "D"},
+			compressed:      true,
+		},
+		{
+			name:            "Partial reduction",
+			profiles:        map[string]CompressionProfile{"B": {Ratio: 0.7}},
+			expectedLatency: 20.5,
+			expectedRouters: []string{"A", "B", "D"},
+			compressed:      true,
+		},
+		{
+			name:            "Encode overhead",
+			profiles:        map[string]CompressionProfile{"B": {Ratio: 0.5, Overhead: 20}, "C": {Ratio: 0.5}},
+			expectedLatency: 25,
+			expectedRouters: []string{"A", "B", "D"},
+			compressed:      false,
+		},
+		{
+			name:            "Encode overhead on a long link",
+			profiles:        map[string]CompressionProfile{"B": {Ratio: 0.5, Overhead: 10}, "C": {Ratio: 0.1, Overhead: 1}},
+			expectedLatency: 24,
+			expectedRouters: []string{"A", "C", "D"},
+			compressed:      true,
+		},
+		{
+			name:            "Short link is not compressed",
+			profiles:        map[string]CompressionProfile{"B": {Ratio: 0.5, MinLatency: 20}, "C": {Ratio: 0.5, MinLatency: 20}},
+			expectedLatency: 25,
+			expectedRouters: []string{"A", "B", "D"},
+			compressed:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := findProfiledLatencyRoute(graph, tt.profiles, "A", "D")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if route.Latency != tt.expectedLatency || !reflect.DeepEqual(route.Routers, tt.expectedRouters) {
+				t.Fatalf("expected %v with latency %.2f, got %v with latency %.2f",
+					tt.expectedRouters, tt.expectedLatency, route.Routers, route.Latency)
+			}
+			if last := route.Hops[len(route.Hops)-1]; last.Compressed != tt.compressed {
+				t.Errorf("expected compressed %v, got %+v", tt.compressed, last)
+			}
+		})
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	graph := map[string][]Router{"A": {{"B", 10}}, "B": {}}
+	invalid := []CompressionProfile{
+		{Ratio: -1},
+		{Overhead: 1},
+		{Ratio: 0.5, Overhead: -1},
+		{Ratio: math.NaN()},
+		{Ratio: 0.5, MinLatency: math.NaN()},
+	}
+	for _, profile := range invalid {
+		profiles := map[string]CompressionProfile{"A": profile}
+		if _, err := findProfiledLatencyRoute(graph, profiles, "A", "B"); !errors.Is(err, ErrInvalidCompressionProfile) {
+			t.Errorf("%+v: expected %v, got %v", profile, ErrInvalidCompressionProfile, err)
+		}
+		if _, err := findProfiledBudgetedRoutes(graph, profiles, "A", "B", 1); !errors.Is(err, ErrInvalidCompressionProfile) {
+			t.Errorf("%+v: expected %v, got %v", profile, ErrInvalidCompressionProfile, err)
+		}
+		if _, err := findProfiledAlternativeRoutes(graph, profiles, "A", "B", 2, AnyRoutes); !errors.Is(err, ErrInvalidCompressionProfile) {
+			t.Errorf("%+v: expected %v, got %v", profile, ErrInvalidCompressionProfile, err)
+		}
+	}
+}